@@ -0,0 +1,143 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"strings"
+)
+
+// StreamAccumulator merges streamed chunks into a CompletionResult: content,
+// refusal, tool call fragments, citations and usage. It lets callers reading
+// the raw SDK stream reuse the package's aggregation. A StreamAccumulator is
+// not safe for concurrent use; feed it from the goroutine reading the stream.
+type StreamAccumulator struct {
+	result  CompletionResult
+	content strings.Builder
+	refusal strings.Builder
+	tokens  int
+
+	onContent   func(string)
+	onCitations func([]Citation)
+	onEvent     func(Delta)
+}
+
+// Delta is one streaming event. Exactly one field is set. Within a chunk
+// events arrive in the order role, citations, tool call fragments, content,
+// finish reason; across chunks the role comes before any content, citations
+// normally precede the content, and the finish reason is the last event of
+// the choice.
+type Delta struct {
+	Content          string            `json:"content,omitempty"`
+	Role             ChatRole          `json:"role,omitempty"`
+	ToolCallFragment *ToolCallFragment `json:"tool_call,omitempty"`
+	FinishReason     string            `json:"finish_reason,omitempty"`
+	Citations        []Citation        `json:"citations,omitempty"`
+}
+
+// ToolCallFragment is part of a streamed tool call. Index is the call's
+// position in CompletionResult.ToolCalls; ID and Name are normally only in a
+// call's first fragment, and Arguments are to be concatenated.
+type ToolCallFragment struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+func (a *StreamAccumulator) emit(d Delta) {
+	if a.onEvent != nil {
+		a.onEvent(d)
+	}
+}
+
+// Add merges one chunk.
+func (a *StreamAccumulator) Add(chunk azopenai.ChatCompletions) {
+	if a.result.ID == "" && chunk.ID != nil {
+		a.result.ID = *chunk.ID
+		a.result.Model = deref(chunk.Model)
+		a.result.Created = deref(chunk.Created)
+	}
+	if u := chunk.Usage; u != nil {
+		a.result.Usage = Usage{
+			PromptTokens:     int(deref(u.PromptTokens)),
+			CompletionTokens: int(deref(u.CompletionTokens)),
+			TotalTokens:      int(deref(u.TotalTokens)),
+		}
+	}
+	for _, choice := range chunk.Choices {
+		if choice.Enhancements != nil {
+			a.result.Enhanced = true
+		}
+		if delta := choice.Delta; delta != nil {
+			a.addDelta(delta)
+		}
+		if choice.FinishReason != nil {
+			a.result.FinishReason = string(*choice.FinishReason)
+			a.emit(Delta{FinishReason: a.result.FinishReason})
+		}
+	}
+}
+
+func (a *StreamAccumulator) addDelta(delta *azopenai.ChatResponseMessage) {
+	if delta.Role != nil {
+		a.result.Role = ChatRole(*delta.Role)
+		a.emit(Delta{Role: a.result.Role})
+	}
+	if delta.Context != nil {
+		cites := citations(delta.Context)
+		if len(cites) > 0 {
+			if a.onCitations != nil {
+				a.onCitations(cites)
+			}
+			a.emit(Delta{Citations: cites})
+		}
+		a.result.Citations = append(a.result.Citations, cites...)
+		a.result.RetrievedDocuments = append(a.result.RetrievedDocuments, retrievedDocuments(delta.Context)...)
+		a.result.SearchQueries = append(a.result.SearchQueries, searchQueries(delta.Context)...)
+		a.result.PartialSearch = a.result.PartialSearch || partialSearch(delta.Context)
+	}
+	a.addToolCalls(delta.ToolCalls)
+	if delta.Refusal != nil {
+		a.refusal.WriteString(*delta.Refusal)
+	}
+	if delta.Content != nil && *delta.Content != "" {
+		a.tokens++
+		a.content.WriteString(*delta.Content)
+		if a.onContent != nil {
+			a.onContent(*delta.Content)
+		}
+		a.emit(Delta{Content: *delta.Content})
+	}
+}
+
+// addToolCalls appends streamed tool call fragments. A fragment with an ID
+// starts a new call; later fragments without one extend the latest call.
+func (a *StreamAccumulator) addToolCalls(calls []azopenai.ChatCompletionsToolCallClassification) {
+	for _, tc := range calls {
+		fn, ok := tc.(*azopenai.ChatCompletionsFunctionToolCall)
+		if !ok {
+			continue
+		}
+		if id := deref(fn.ID); id != "" || len(a.result.ToolCalls) == 0 {
+			a.result.ToolCalls = append(a.result.ToolCalls, ToolCall{ID: id})
+		}
+		index := len(a.result.ToolCalls) - 1
+		last := &a.result.ToolCalls[index]
+		frag := ToolCallFragment{Index: index, ID: deref(fn.ID)}
+		if fn.Function != nil {
+			frag.Name = deref(fn.Function.Name)
+			frag.Arguments = deref(fn.Function.Arguments)
+			last.Name += frag.Name
+			last.Arguments += frag.Arguments
+		}
+		a.emit(Delta{ToolCallFragment: &frag})
+	}
+}
+
+// Result returns the result accumulated so far. It can be called at any
+// point, including after a stream error.
+func (a *StreamAccumulator) Result() CompletionResult {
+	result := a.result
+	result.Content = a.content.String()
+	result.classifyGrounding(a.refusal.String())
+	return result
+}
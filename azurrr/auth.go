@@ -0,0 +1,90 @@
+package azurrr
+
+import (
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// AuthMethod is how On Your Data authenticates to the search service or the
+// embedding endpoint. The empty value means AuthAPIKey.
+type AuthMethod string
+
+const (
+	AuthAPIKey                AuthMethod = "api_key"
+	AuthSystemManagedIdentity AuthMethod = "system_assigned_managed_identity"
+	AuthUserManagedIdentity   AuthMethod = "user_assigned_managed_identity"
+	AuthAccessToken           AuthMethod = "access_token"
+)
+
+// checkSearchAuth validates that SearchAuth has the fields it needs.
+func (c Config) checkSearchAuth() error {
+	if c.SearchEndpoint == "" {
+		return nil
+	}
+	switch c.SearchAuth {
+	case "", AuthAPIKey, AuthSystemManagedIdentity:
+	case AuthUserManagedIdentity:
+		if c.SearchManagedIdentityID == "" {
+			return fmt.Errorf("azurrr: SEARCH_AUTH_TYPE %q needs SEARCH_MANAGED_IDENTITY_RESOURCE_ID", c.SearchAuth)
+		}
+	case AuthAccessToken:
+		if c.SearchAccessToken == "" {
+			return fmt.Errorf("azurrr: SEARCH_AUTH_TYPE %q needs SEARCH_ACCESS_TOKEN", c.SearchAuth)
+		}
+	default:
+		return fmt.Errorf("azurrr: unknown SEARCH_AUTH_TYPE %q", c.SearchAuth)
+	}
+	return nil
+}
+
+// checkEmbeddingAuth validates that EmbeddingAuth is usable and has the
+// fields it needs.
+func (c Config) checkEmbeddingAuth() error {
+	if c.SearchEndpoint == "" || c.EmbeddingEndpoint == "" {
+		return nil
+	}
+	switch c.EmbeddingAuth {
+	case "", AuthAPIKey:
+	case AuthAccessToken:
+		if c.EmbeddingAccessToken == "" {
+			return fmt.Errorf("azurrr: EMBEDDING_AUTH_TYPE %q needs EMBEDDING_ACCESS_TOKEN", c.EmbeddingAuth)
+		}
+	case AuthSystemManagedIdentity, AuthUserManagedIdentity:
+		return fmt.Errorf("azurrr: EMBEDDING_AUTH_TYPE %q is not supported; an embedding endpoint only accepts %q or %q", c.EmbeddingAuth, AuthAPIKey, AuthAccessToken)
+	default:
+		return fmt.Errorf("azurrr: unknown EMBEDDING_AUTH_TYPE %q", c.EmbeddingAuth)
+	}
+	return nil
+}
+
+// searchAuth builds the search service authentication for SearchAuth.
+func (c Config) searchAuth() azopenai.OnYourDataAuthenticationOptionsClassification {
+	switch c.SearchAuth {
+	case AuthSystemManagedIdentity:
+		return &azopenai.OnYourDataSystemAssignedManagedIdentityAuthenticationOptions{}
+	case AuthUserManagedIdentity:
+		return &azopenai.OnYourDataUserAssignedManagedIdentityAuthenticationOptions{
+			ManagedIdentityResourceID: to.Ptr(c.SearchManagedIdentityID),
+		}
+	case AuthAccessToken:
+		return &azopenai.OnYourDataAccessTokenAuthenticationOptions{AccessToken: to.Ptr(c.SearchAccessToken)}
+	default:
+		return &azopenai.OnYourDataAPIKeyAuthenticationOptions{Key: to.Ptr(c.SearchAPIKey)}
+	}
+}
+
+// embeddingAuth builds the embedding endpoint authentication for
+// EmbeddingAuth.
+func (c Config) embeddingAuth() azopenai.OnYourDataVectorSearchAuthenticationOptionsClassification {
+	if c.EmbeddingAuth == AuthAccessToken {
+		return &azopenai.OnYourDataVectorSearchAccessTokenAuthenticationOptions{
+			Type:        to.Ptr(azopenai.OnYourDataVectorSearchAuthenticationTypeAccessToken),
+			AccessToken: to.Ptr(c.EmbeddingAccessToken),
+		}
+	}
+	return &azopenai.OnYourDataVectorSearchAPIKeyAuthenticationOptions{
+		Type: to.Ptr(azopenai.OnYourDataVectorSearchAuthenticationTypeAPIKey),
+		Key:  to.Ptr(c.embeddingKey()),
+	}
+}
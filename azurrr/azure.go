@@ -4,82 +4,92 @@ import (
 	"context"
 	"fmt"
 	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"log"
 	"os"
 )
 
-var endpointType azopenai.OnYourDataVectorizationSourceType = "endpoint"
-var authType azopenai.OnYourDataVectorSearchAuthenticationType = "api_key"
+// Params are the per-call sampling settings. Nil fields fall back to the
+// configured defaults and then to the package Default variables.
+type Params struct {
+	MaxTokens        *int32
+	Temperature      *float32
+	TopP             *float32
+	FrequencyPenalty *float32
+	PresencePenalty  *float32
+	// Sampling chooses which of Temperature and TopP is sent; the other is
+	// omitted, as Azure advises against tuning both.
+	Sampling SamplingMode
 
-func StartAzure(ctx context.Context) {
-	azureOpenAIKey := os.Getenv("AZURE_OPENAI_API_KEY")
-	modelDeploymentID := os.Getenv("DEPLOYMENT_NAME")
-	azureOpenAIEndpoint := os.Getenv("AOAI_ENDPOINT_URL")
-	searchIndex := os.Getenv("SEARCH_INDEX_NAME")
-	searchEndpoint := os.Getenv("SEARCH_ENDPOINT")
-	searchAPIKey := os.Getenv("SEARCH_KEY")
-	embedingEndpoint := os.Getenv("EMBEDDING_ENDPOINT")
+	// Tools are the tools the model may call. ParallelToolCalls, when set,
+	// enables or disables parallel tool calls; nil leaves the service
+	// default. With it disabled at most one tool call is returned per round.
+	Tools             []azopenai.ChatCompletionsToolDefinitionClassification
+	ParallelToolCalls *bool
+
+	// FileFilter restricts grounding to the documents whose file path field
+	// matches one of these values.
+	FileFilter []string
+	// Filter overrides Config.SearchFilter for one call. It is combined with
+	// FileFilter; both must match.
+	Filter *string
+
+	// InScope overrides Config.InScope for one grounded call. Setting it to
+	// false lets the model answer from general knowledge when the index has
+	// nothing relevant, at the cost of answers that are no longer backed by
+	// the documents and so are more likely to be hallucinated. Check
+	// CompletionResult.ScopeEnforced and Grounded before trusting them.
+	InScope *bool
 
-	log.Printf("Azure OpenAI Endpoint: %s", azureOpenAIEndpoint)
-	log.Printf("Model Deployment ID: %s", modelDeploymentID)
-	log.Printf("Search Endpoint: %s", searchEndpoint)
-	log.Printf("Search Index: %s", searchIndex)
-	keyCredential := azcore.NewKeyCredential(azureOpenAIKey)
+	// Metadata, such as a tenant or request ID, is recorded on the call's
+	// trace span and request log and attached to its errors as a
+	// *MetadataError. It is never sent to Azure.
+	Metadata map[string]string
+}
+
+// SamplingMode selects the sampling parameter a call sends.
+type SamplingMode string
 
-	client, err := azopenai.NewClientWithKeyCredential(azureOpenAIEndpoint, keyCredential, nil)
+const (
+	// SampleTemperature sends Temperature only. It is the default.
+	SampleTemperature SamplingMode = "temperature"
+	// SampleTopP sends TopP only.
+	SampleTopP SamplingMode = "top_p"
+)
+
+func StartAzure(ctx context.Context) {
+	cfg, err := LoadConfig()
 	if err != nil {
-		log.Fatalf("ERROrwerweR: %+v", err)
+		log.Fatalf("ERROR: %+v", err)
 	}
 
-	systemPrompt := "You are an AI assistant that helps people find information "
-	userMessage := "tell me a joke"
-	queryType := azopenai.AzureSearchQueryType("vector_simple_hybrid")
-	messages := []azopenai.ChatRequestMessageClassification{
-		&azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(systemPrompt)},
-		&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(userMessage)},
+	endpoint, err := cfg.endpoint()
+	if err != nil {
+		log.Fatalf("ERROR: %+v", err)
 	}
+	log.Printf("Azure OpenAI Endpoint: %s", endpoint)
+	log.Printf("Model Deployment ID: %s", cfg.Deployment)
+	log.Printf("Search Endpoint: %s", cfg.SearchEndpoint)
+	log.Printf("Search Index: %s", cfg.SearchIndex)
 
-	resp, err := client.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
-		Messages:         messages,
-		MaxTokens:        to.Ptr[int32](800),
-		Temperature:      to.Ptr[float32](0.7),
-		TopP:             to.Ptr[float32](0.95),
-		FrequencyPenalty: to.Ptr[float32](0),
-		PresencePenalty:  to.Ptr[float32](0),
-
-		AzureExtensionsOptions: []azopenai.AzureChatExtensionConfigurationClassification{
-			&azopenai.AzureSearchChatExtensionConfiguration{
-				Parameters: &azopenai.AzureSearchChatExtensionParameters{
-					Endpoint:  &searchEndpoint,
-					IndexName: &searchIndex,
-					Authentication: &azopenai.OnYourDataAPIKeyAuthenticationOptions{
-						Key: &searchAPIKey,
-					},
-					Strictness:    to.Ptr[int32](5),
-					InScope:       to.Ptr[bool](true),
-					TopNDocuments: to.Ptr[int32](5),
-					QueryType:     &queryType,
-					EmbeddingDependency: &azopenai.OnYourDataEndpointVectorizationSource{
-						Authentication: &azopenai.OnYourDataVectorSearchAPIKeyAuthenticationOptions{
-							Type: &authType,
-							Key:  &azureOpenAIKey,
-						},
-						Endpoint: &embedingEndpoint,
-						Type:     &endpointType,
-					},
-					SemanticConfiguration: to.Ptr("azureml-default"),
-				},
-			},
-		},
-		DeploymentName: &modelDeploymentID,
-	}, nil)
-
+	client, err := NewClient(cfg, nil)
 	if err != nil {
+		log.Fatalf("ERROrwerweR: %+v", err)
+	}
+	if err := client.startAzure(ctx); err != nil {
 		log.Fatalf("ERROR: %+v", err)
 	}
+}
+
+// startAzure is the call StartAzure makes once the client is built.
+func (c *Client) startAzure(ctx context.Context) error {
+	userMessage := "tell me a joke"
+	result, err := c.Ask(ctx, userMessage, Params{})
+	if err != nil {
+		return err
+	}
 
-	fmt.Fprintf(os.Stderr, "Extensions Context Role: %s\nExtensions Context (length): %d\n", *resp.Choices[0].Message.Role, len(*resp.Choices[0].Message.Content))
-	fmt.Fprintf(os.Stderr, "ChatRole: %s\nChat content: %s\n", *resp.Choices[0].Message.Role, *resp.Choices[0].Message.Content)
+	fmt.Fprintf(os.Stderr, "Extensions Context Role: %s\nExtensions Context (length): %d\n", result.Role, len(result.Content))
+	fmt.Fprintf(os.Stderr, "Retrieved documents: %d\n", len(result.RetrievedDocuments))
+	fmt.Fprintf(os.Stderr, "ChatRole: %s\nChat content: %s\n", result.Role, result.Content)
+	return nil
 }
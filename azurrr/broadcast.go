@@ -0,0 +1,174 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"sync"
+	"sync/atomic"
+)
+
+// BroadcastPolicy decides what a Broadcast does when a subscriber's buffer
+// is full.
+type BroadcastPolicy string
+
+const (
+	// BroadcastDrop skips the event for that subscriber only, so a slow
+	// consumer never holds up the others or the stream.
+	BroadcastDrop BroadcastPolicy = "drop"
+	// BroadcastBlock waits for the subscriber, so every consumer sees every
+	// event but the slowest one paces the stream.
+	BroadcastBlock BroadcastPolicy = "block"
+)
+
+// ErrBroadcastUsed is returned by StreamBroadcast for a Broadcast that has
+// already carried a stream.
+var ErrBroadcastUsed = errors.New("azurrr: Broadcast has already carried a stream")
+
+// Broadcast fans the events of one stream out to several consumers, such as
+// a UI and a logger, from a single Azure call. Subscribe before streaming;
+// every subscriber channel is closed when the stream ends. A Broadcast
+// carries one stream; create a new one for the next.
+type Broadcast struct {
+	policy BroadcastPolicy
+	buffer int
+
+	mu      sync.Mutex
+	subs    []*subscriber
+	started bool
+	closed  bool
+}
+
+type subscriber struct {
+	ch      chan Delta
+	dropped atomic.Int64
+}
+
+// NewBroadcast creates a Broadcast whose subscribers each buffer up to
+// buffer events.
+func NewBroadcast(policy BroadcastPolicy, buffer int) (*Broadcast, error) {
+	switch policy {
+	case BroadcastDrop, BroadcastBlock:
+	default:
+		return nil, fmt.Errorf("azurrr: unknown broadcast policy %q", policy)
+	}
+	if buffer < 0 {
+		return nil, fmt.Errorf("azurrr: negative broadcast buffer %d", buffer)
+	}
+	return &Broadcast{policy: policy, buffer: buffer}, nil
+}
+
+// Subscribe returns a channel receiving every event. It is closed once the
+// stream ends; subscribing after that returns a closed channel.
+func (b *Broadcast) Subscribe() <-chan Delta {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &subscriber{ch: make(chan Delta, b.buffer)}
+	if b.closed {
+		close(s.ch)
+		return s.ch
+	}
+	b.subs = append(b.subs, s)
+	return s.ch
+}
+
+// SubscribeFunc calls fn with every event on a goroutine of its own. The
+// returned func waits until fn has seen the last event.
+func (b *Broadcast) SubscribeFunc(fn func(Delta)) (wait func()) {
+	ch := b.Subscribe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for d := range ch {
+			fn(d)
+		}
+	}()
+	return func() { <-done }
+}
+
+// Dropped reports how many events were skipped, summed over all
+// subscribers: those BroadcastDrop found no room for, and those
+// BroadcastBlock gave up on after the stream's context was done.
+func (b *Broadcast) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, s := range b.subs {
+		n += int(s.dropped.Load())
+	}
+	return n
+}
+
+// start claims b for one stream.
+func (b *Broadcast) start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started || b.closed {
+		return ErrBroadcastUsed
+	}
+	b.started = true
+	return nil
+}
+
+// publish delivers d to every subscriber. Under BroadcastBlock it gives up
+// on a subscriber that is not reading once ctx is done.
+func (b *Broadcast) publish(ctx context.Context, d Delta) {
+	b.mu.Lock()
+	subs := b.subs
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return
+	}
+	for _, s := range subs {
+		if b.policy == BroadcastBlock {
+			select {
+			case s.ch <- d:
+			case <-ctx.Done():
+				s.dropped.Add(1)
+			}
+			continue
+		}
+		select {
+		case s.ch <- d:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (b *Broadcast) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subs {
+		close(s.ch)
+	}
+}
+
+// StreamBroadcast runs Stream, publishing its events to b's subscribers
+// alongside any OnEvent in opts, and closes them when the stream ends. It
+// returns ErrBroadcastUsed without streaming if b has carried a stream
+// before.
+func (c *Client) StreamBroadcast(ctx context.Context, b *Broadcast, messages []azopenai.ChatRequestMessageClassification, p Params, opts *StreamOptions) (CompletionResult, error) {
+	if err := b.start(); err != nil {
+		return CompletionResult{}, err
+	}
+	defer b.close()
+	o := StreamOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	next := o.OnEvent
+	o.OnEvent = func(d Delta) {
+		b.publish(ctx, d)
+		if next != nil {
+			next(d)
+		}
+	}
+	return c.Stream(ctx, messages, p, &o)
+}
@@ -0,0 +1,79 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func echoClient(t *testing.T) *Client {
+	t.Helper()
+	c, err := NewClient(Config{Echo: true, Deployment: "echo", MaxTokens: 10}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+func TestBroadcastDeliversToEverySubscriber(t *testing.T) {
+	c := echoClient(t)
+	b, err := NewBroadcast(BroadcastBlock, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [2]string
+	waits := make([]func(), len(got))
+	for i := range got {
+		waits[i] = b.SubscribeFunc(func(d Delta) { got[i] += d.Content })
+	}
+	result, err := c.StreamBroadcast(context.Background(), b, userMessages("hello there"), Params{}, nil)
+	if err != nil {
+		t.Fatalf("StreamBroadcast: %v", err)
+	}
+	for i, wait := range waits {
+		wait()
+		if got[i] != result.Content {
+			t.Errorf("subscriber %d saw %q, want %q", i, got[i], result.Content)
+		}
+	}
+}
+
+func TestBroadcastCarriesOneStream(t *testing.T) {
+	c := echoClient(t)
+	b, _ := NewBroadcast(BroadcastDrop, 8)
+	b.Subscribe()
+	if _, err := c.StreamBroadcast(context.Background(), b, userMessages("hi"), Params{}, nil); err != nil {
+		t.Fatalf("first StreamBroadcast: %v", err)
+	}
+	if _, err := c.StreamBroadcast(context.Background(), b, userMessages("hi"), Params{}, nil); !errors.Is(err, ErrBroadcastUsed) {
+		t.Fatalf("second StreamBroadcast err = %v, want ErrBroadcastUsed", err)
+	}
+}
+
+func TestBroadcastBlockHonoursCancellation(t *testing.T) {
+	c := echoClient(t)
+	b, _ := NewBroadcast(BroadcastBlock, 0)
+	b.Subscribe() // never read
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.StreamBroadcast(ctx, b, userMessages("hello there"), Params{}, nil)
+		done <- err
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamBroadcast hung on a subscriber that stopped reading")
+	}
+	if b.Dropped() == 0 {
+		t.Error("Dropped = 0, want the events the stuck subscriber missed")
+	}
+}
+
+func TestNewBroadcastRejectsUnknownPolicy(t *testing.T) {
+	if _, err := NewBroadcast("fanout", 1); err == nil {
+		t.Error("NewBroadcast accepted an unknown policy")
+	}
+}
@@ -0,0 +1,83 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// Complete sends fully-formed messages as a chat completions call without
+// any search extension. It is the primitive the grounded calls build on.
+// Besides retries, an answer cut off at the token limit is continued in up
+// to Config.MaxContinuations further calls.
+func (c *Client) Complete(ctx context.Context, messages []azopenai.ChatRequestMessageClassification, p Params) (CompletionResult, error) {
+	return c.getChatCompletions(WithMetadata(ctx, p.Metadata), c.completionOptions(messages, p))
+}
+
+// Raw sends opts as they are, for callers who have built the SDK options
+// themselves, filling DeploymentName and an unset token limit from the
+// config and unset sampling fields from the package defaults. The call
+// still goes through validation, the token budget check, retries and result
+// parsing.
+func (c *Client) Raw(ctx context.Context, opts azopenai.ChatCompletionsOptions) (CompletionResult, error) {
+	if deref(opts.DeploymentName) == "" {
+		if c.cfg.Deployment == "" {
+			return CompletionResult{}, errors.New("azurrr: Raw: no deployment in the options or the config")
+		}
+		opts.DeploymentName = to.Ptr(c.cfg.Deployment)
+	}
+	if opts.MaxTokens == nil && opts.MaxCompletionTokens == nil {
+		opts.MaxTokens = c.cfg.maxTokens(deref(opts.DeploymentName), Params{})
+	}
+	applyRawDefaults(&opts)
+	return c.getChatCompletions(ctx, opts)
+}
+
+// Ask answers question grounded on the configured search index, using the
+// configured system prompt.
+func (c *Client) Ask(ctx context.Context, question string, p Params) (CompletionResult, error) {
+	opts, err := c.groundedOptions(c.askMessages(question), p)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	return c.getChatCompletions(WithMetadata(ctx, p.Metadata), opts)
+}
+
+func (c *Client) askMessages(question string) []azopenai.ChatRequestMessageClassification {
+	turns := make([]Turn, 0, len(c.cfg.ScopeExamples)+3)
+	turns = append(turns, c.instructionTurns()...)
+	turns = append(turns, c.cfg.ScopeExamples...)
+	turns = append(turns, Turn{Role: string(RoleUser), Content: question})
+	// The roles are fixed here and ScopeExamples are checked by NewClient.
+	messages, _ := ToMessages(turns)
+	return messages
+}
+
+// instructionTurns are the system prompt and, when configured, the
+// developer prompt that open every conversation.
+func (c *Client) instructionTurns() []Turn {
+	turns := []Turn{{Role: string(RoleSystem), Content: c.systemPrompt()}}
+	if c.cfg.DeveloperPrompt != "" {
+		turns = append(turns, Turn{Role: string(RoleDeveloper), Content: c.cfg.DeveloperPrompt})
+	}
+	return turns
+}
+
+// systemPrompt is SystemPrompt followed by the configured role information,
+// fallback instruction and local time.
+func (c *Client) systemPrompt() string {
+	prompt := c.cfg.SystemPrompt
+	if c.cfg.RoleInformation != "" {
+		prompt += "\n\n" + c.cfg.RoleInformation
+	}
+	if c.cfg.FallbackMessage != "" {
+		prompt += fmt.Sprintf("\n\nIf the retrieved documents do not contain the answer, reply with exactly: %s", c.cfg.FallbackMessage)
+	}
+	if c.location != nil {
+		now := c.clock.Now().In(c.location)
+		prompt += fmt.Sprintf("\n\nThe current date and time is %s (%s).", now.Format("Monday, 2 January 2006 15:04 MST"), c.location)
+	}
+	return prompt
+}
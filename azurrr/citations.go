@@ -0,0 +1,155 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CitationFormatter renders an answer together with its citations. Answers
+// grounded by On Your Data reference sources inline as [doc1], [doc2], ...
+// where the number is the 1-based position in the citations.
+type CitationFormatter interface {
+	Format(content string, citations []Citation) (string, error)
+}
+
+// FormatCitations renders the result's content and citations with f.
+func (r CompletionResult) FormatCitations(f CitationFormatter) (string, error) {
+	return f.Format(r.Content, r.Citations)
+}
+
+// lowConfidence reports whether a grounded result cites fewer sources than
+// MinCitations. Ungrounded calls are never flagged.
+func (c Config) lowConfidence(opts azopenai.ChatCompletionsOptions, r CompletionResult) bool {
+	return c.MinCitations > 0 && searchParameters(opts) != nil && len(r.Citations) < c.MinCitations
+}
+
+// widenSearch returns a copy of opts retrieving LowConfidenceTopNDocuments
+// documents, or false when that would not retrieve more than opts does.
+func (c Config) widenSearch(opts azopenai.ChatCompletionsOptions) (azopenai.ChatCompletionsOptions, bool) {
+	params := searchParameters(opts)
+	if params == nil || c.LowConfidenceTopNDocuments <= deref(params.TopNDocuments) {
+		return opts, false
+	}
+	wider := *params
+	wider.TopNDocuments = to.Ptr(c.LowConfidenceTopNDocuments)
+	exts := make([]azopenai.AzureChatExtensionConfigurationClassification, len(opts.AzureExtensionsOptions))
+	for i, ext := range opts.AzureExtensionsOptions {
+		if search, ok := ext.(*azopenai.AzureSearchChatExtensionConfiguration); ok && search.Parameters == params {
+			ext = &azopenai.AzureSearchChatExtensionConfiguration{Parameters: &wider}
+		}
+		exts[i] = ext
+	}
+	opts.AzureExtensionsOptions = exts
+	return opts, true
+}
+
+// searchParameters returns the Azure Search extension parameters of opts,
+// or nil for an ungrounded request.
+func searchParameters(opts azopenai.ChatCompletionsOptions) *azopenai.AzureSearchChatExtensionParameters {
+	for _, ext := range opts.AzureExtensionsOptions {
+		if search, ok := ext.(*azopenai.AzureSearchChatExtensionConfiguration); ok && search.Parameters != nil {
+			return search.Parameters
+		}
+	}
+	return nil
+}
+
+var docRefPattern = regexp.MustCompile(`\[doc(\d+)\]`)
+
+// replaceDocRefs rewrites every [docN] marker with repl(N, citation). Markers
+// that point past the citation list are left as they are.
+func replaceDocRefs(content string, citations []Citation, repl func(n int, c Citation) string) string {
+	return docRefPattern.ReplaceAllStringFunc(content, func(m string) string {
+		n, err := strconv.Atoi(docRefPattern.FindStringSubmatch(m)[1])
+		if err != nil || n < 1 || n > len(citations) {
+			return m
+		}
+		return repl(n, citations[n-1])
+	})
+}
+
+func citationLabel(c Citation) string {
+	for _, s := range []string{c.Title, c.FilePath, c.URL} {
+		if s != "" {
+			return s
+		}
+	}
+	return "source"
+}
+
+// FootnoteFormatter turns [docN] markers into [N] and appends numbered
+// footnotes.
+type FootnoteFormatter struct{}
+
+func (FootnoteFormatter) Format(content string, citations []Citation) (string, error) {
+	out := replaceDocRefs(content, citations, func(n int, _ Citation) string {
+		return fmt.Sprintf("[%d]", n)
+	})
+	if len(citations) == 0 {
+		return out, nil
+	}
+	var b strings.Builder
+	b.WriteString(out)
+	b.WriteString("\n")
+	for i, c := range citations {
+		fmt.Fprintf(&b, "\n[%d] %s", i+1, citationLabel(c))
+		if c.URL != "" && c.URL != citationLabel(c) {
+			fmt.Fprintf(&b, " (%s)", c.URL)
+		}
+	}
+	return b.String(), nil
+}
+
+// MarkdownFormatter links [docN] markers to their URLs and appends a
+// Markdown list of sources.
+type MarkdownFormatter struct{}
+
+func (MarkdownFormatter) Format(content string, citations []Citation) (string, error) {
+	out := replaceDocRefs(content, citations, func(n int, c Citation) string {
+		if c.URL == "" {
+			return fmt.Sprintf("[%d]", n)
+		}
+		return fmt.Sprintf("[[%d]](%s)", n, c.URL)
+	})
+	if len(citations) == 0 {
+		return out, nil
+	}
+	var b strings.Builder
+	b.WriteString(out)
+	b.WriteString("\n\n**Sources**\n")
+	for i, c := range citations {
+		if c.URL != "" {
+			fmt.Fprintf(&b, "\n%d. [%s](%s)", i+1, citationLabel(c), c.URL)
+		} else {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, citationLabel(c))
+		}
+	}
+	return b.String(), nil
+}
+
+// JSONFormatter leaves the content untouched and appends the citations as a
+// fenced JSON block for clients that render sources themselves.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(content string, citations []Citation) (string, error) {
+	type entry struct {
+		Ref      string `json:"ref"`
+		Title    string `json:"title,omitempty"`
+		URL      string `json:"url,omitempty"`
+		FilePath string `json:"filepath,omitempty"`
+	}
+	entries := make([]entry, len(citations))
+	for i, c := range citations {
+		entries[i] = entry{Ref: fmt.Sprintf("doc%d", i+1), Title: c.Title, URL: c.URL, FilePath: c.FilePath}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return content + "\n\n```json\n" + string(data) + "\n```", nil
+}
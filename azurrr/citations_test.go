@@ -0,0 +1,78 @@
+package azurrr
+
+import "testing"
+
+func TestCitationFormatters(t *testing.T) {
+	cites := []Citation{
+		{Title: "Guide", URL: "https://example.com/guide"},
+		{FilePath: "docs/faq.md"},
+	}
+	tests := []struct {
+		name      string
+		f         CitationFormatter
+		content   string
+		citations []Citation
+		want      string
+	}{
+		{
+			name:      "footnote",
+			f:         FootnoteFormatter{},
+			content:   "See [doc1] and [doc2].",
+			citations: cites,
+			want:      "See [1] and [2].\n\n[1] Guide (https://example.com/guide)\n[2] docs/faq.md",
+		},
+		{
+			name:      "footnote out of range",
+			f:         FootnoteFormatter{},
+			content:   "See [doc3] and [doc0].",
+			citations: cites,
+			want:      "See [doc3] and [doc0].\n\n[1] Guide (https://example.com/guide)\n[2] docs/faq.md",
+		},
+		{
+			name:    "footnote no citations",
+			f:       FootnoteFormatter{},
+			content: "See [doc1].",
+			want:    "See [doc1].",
+		},
+		{
+			name:      "markdown",
+			f:         MarkdownFormatter{},
+			content:   "See [doc1] and [doc2].",
+			citations: cites,
+			want:      "See [[1]](https://example.com/guide) and [2].\n\n**Sources**\n\n1. [Guide](https://example.com/guide)\n2. docs/faq.md",
+		},
+		{
+			name:      "markdown out of range",
+			f:         MarkdownFormatter{},
+			content:   "See [doc9].",
+			citations: cites[:1],
+			want:      "See [doc9].\n\n**Sources**\n\n1. [Guide](https://example.com/guide)",
+		},
+		{
+			name:      "json",
+			f:         JSONFormatter{},
+			content:   "See [doc1] and [doc5].",
+			citations: cites,
+			want: "See [doc1] and [doc5].\n\n```json\n[\n" +
+				"  {\n    \"ref\": \"doc1\",\n    \"title\": \"Guide\",\n    \"url\": \"https://example.com/guide\"\n  },\n" +
+				"  {\n    \"ref\": \"doc2\",\n    \"filepath\": \"docs/faq.md\"\n  }\n]\n```",
+		},
+		{
+			name:    "json no citations",
+			f:       JSONFormatter{},
+			content: "Nothing cited.",
+			want:    "Nothing cited.\n\n```json\n[]\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.f.Format(tt.content, tt.citations)
+			if err != nil {
+				t.Fatalf("Format: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientOptions contains optional settings for Client. Pass nil to accept the
+// defaults.
+//
+// Telemetry.ApplicationID is prefixed to the User-Agent so requests can be
+// identified in Azure logs. It defaults to the package name and version and
+// must be at most 24 characters without whitespace.
+//
+// Retry is handed to the SDK pipeline unchanged, giving full control over
+// status codes, try timeout and delays. The pipeline retries every HTTP
+// attempt, while Config.MaxRetries retries the whole call on top of it, so a
+// custom Retry that still retries (MaxRetries other than -1) cannot be
+// combined with Config.MaxRetries; NewClient rejects that combination rather
+// than multiplying the attempts.
+type ClientOptions struct {
+	azopenai.ClientOptions
+
+	// Validators run, in order, against the outgoing messages before every
+	// call. The first error aborts the call without contacting Azure.
+	Validators []Validator
+
+	// Outbound transformers rewrite, in order, the text of every outgoing
+	// message before validation and sending. Inbound transformers rewrite
+	// the final answer content. Streamed deltas cannot be rewritten, so with
+	// inbound transformers Stream refuses OnDelta, OnEvent and Transcript
+	// with ErrInboundStream. The first error aborts the call.
+	Outbound []Transformer
+	Inbound  []Transformer
+
+	// Clock drives retry backoff and embeddings pacing. Defaults to the real clock.
+	Clock Clock
+
+	// ContentType and Accept override the request headers the SDK sends,
+	// for API management layers with strict content negotiation. Empty
+	// keeps the SDK's values.
+	ContentType string
+	Accept      string
+
+	// Connections tunes connection reuse by building the transport with
+	// NewTransport. It cannot be combined with Transport.
+	Connections *ConnectionOptions
+
+	// Credential authenticates with Entra ID tokens instead of
+	// Config.APIKey.
+	Credential CredentialProvider
+
+	// EmbeddingCache replaces the in-memory cache Config.EmbeddingCacheSize
+	// would build, for example with one shared across processes.
+	EmbeddingCache EmbeddingCache
+}
+
+// Client wraps the Azure OpenAI client with the package configuration.
+type Client struct {
+	cfg        Config
+	chat       *azopenai.Client
+	validators []Validator
+	outbound   []Transformer
+	inbound    []Transformer
+	clock      Clock
+	limiter    *limiter
+	// embedLimiter paces Embed; nil when unlimited.
+	embedLimiter *tokenBucket
+	// embedCache serves repeated Embed inputs; nil when disabled.
+	embedCache EmbeddingCache
+	// flights coalesces identical calls; nil unless CoalesceRequests is set.
+	flights *flightGroup
+	// rest calls the data plane endpoints the SDK does not cover, such as
+	// stored completions and the deployments listing.
+	rest     runtime.Pipeline
+	tracer   tracing.Tracer
+	location *time.Location
+}
+
+// NewClient creates a Client authenticated with options.Credential when set
+// and cfg.APIKey otherwise.
+func NewClient(cfg Config, options *ClientOptions) (*Client, error) {
+	if options == nil {
+		options = &ClientOptions{}
+	}
+	if err := cfg.validate(options.Credential == nil); err != nil {
+		return nil, err
+	}
+	cfg.warnUnusedEmbedding()
+	// Validate has already rejected a bad resource name or time zone.
+	endpoint, _ := cfg.endpoint()
+	var location *time.Location
+	if cfg.PromptTimezone != "" {
+		location, _ = time.LoadLocation(cfg.PromptTimezone)
+	}
+	azOpts := options.ClientOptions
+	if retryOptionsSet(azOpts.Retry) && azOpts.Retry.MaxRetries != -1 && cfg.MaxRetries > 0 {
+		return nil, errors.New("azurrr: ClientOptions.Retry and Config.MaxRetries both retry; set Retry.MaxRetries to -1 or MaxRetries to 0")
+	}
+	if options.Connections != nil {
+		if azOpts.Transport != nil {
+			return nil, errors.New("azurrr: ClientOptions.Connections and Transport are mutually exclusive")
+		}
+		azOpts.Transport = NewTransport(*options.Connections)
+	}
+	if err := applicationID(&azOpts.Telemetry); err != nil {
+		return nil, err
+	}
+	if options.ContentType != "" || options.Accept != "" {
+		hp, err := newHeaderPolicy(options.ContentType, options.Accept)
+		if err != nil {
+			return nil, err
+		}
+		azOpts.PerCallPolicies = append(append([]policy.Policy{}, azOpts.PerCallPolicies...), hp)
+	}
+	if cfg.StoreCompletions {
+		azOpts.PerRetryPolicies = append(append([]policy.Policy{}, azOpts.PerRetryPolicies...), storePolicy{})
+	}
+	var chat *azopenai.Client
+	var err error
+	if options.Credential != nil {
+		chat, err = azopenai.NewClient(endpoint, options.Credential, &azOpts)
+	} else {
+		chat, err = azopenai.NewClientWithKeyCredential(endpoint, azcore.NewKeyCredential(cfg.APIKey), &azOpts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Echo {
+		log.Printf("azurrr: WARNING: AZURRR_ECHO is set; chat calls return canned echo completions and never reach Azure")
+	}
+	clock := options.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	embedCache := options.EmbeddingCache
+	if embedCache == nil && cfg.EmbeddingCacheSize > 0 {
+		embedCache = newMemoryEmbeddingCache(clock, cfg.EmbeddingCacheSize, cfg.EmbeddingCacheTTL)
+	}
+	return &Client{
+		cfg:          cfg,
+		chat:         chat,
+		validators:   options.Validators,
+		outbound:     options.Outbound,
+		inbound:      options.Inbound,
+		clock:        clock,
+		limiter:      newLimiter(cfg.MaxConcurrent, cfg.OverflowPolicy),
+		embedLimiter: newTokenBucket(clock, cfg.EmbeddingRequestsPerMinute),
+		embedCache:   embedCache,
+		flights:      newFlightGroup(cfg.CoalesceRequests),
+		rest:         newRESTPipeline(cfg, options.Credential, &azOpts.ClientOptions),
+		tracer:       options.TracingProvider.NewTracer(moduleName, moduleVersion),
+		location:     location,
+	}, nil
+}
+
+// getChatCompletions sends a non-streaming request and parses the response.
+func (c *Client) getChatCompletions(ctx context.Context, opts azopenai.ChatCompletionsOptions) (result CompletionResult, err error) {
+	if opts.Messages, err = c.transformMessages(opts.Messages); err != nil {
+		return CompletionResult{}, err
+	}
+	ctx, endSpan := c.startSpan(ctx, "azurrr.GetChatCompletions", opts.Messages)
+	defer func() { err = endSpan(err) }()
+	if err := c.validate(opts.Messages); err != nil {
+		return CompletionResult{}, err
+	}
+	if err := c.cfg.checkTokenBudget(&opts); err != nil {
+		return CompletionResult{}, err
+	}
+	result, err = c.sendContinued(ctx, opts)
+	if err != nil || !c.cfg.lowConfidence(opts, result) {
+		return c.inboundResult(result, err)
+	}
+	// The wider search is best effort: if it fails, the first answer is
+	// still returned, flagged.
+	if wider, ok := c.cfg.widenSearch(opts); ok {
+		if retry, err := c.sendContinued(ctx, wider); err == nil {
+			result, opts = retry, wider
+		}
+	}
+	result.LowConfidence = c.cfg.lowConfidence(opts, result)
+	return c.inboundResult(result, nil)
+}
+
+// send makes the call, coalesced with identical in-flight calls when
+// configured. Requests HashRequest cannot key are sent on their own.
+func (c *Client) send(ctx context.Context, opts azopenai.ChatCompletionsOptions) (CompletionResult, error) {
+	if c.flights != nil {
+		if key := HashRequest(opts); key != "" {
+			return c.flights.do(ctx, key, func(ctx context.Context) (CompletionResult, error) {
+				return c.sendChatCompletions(ctx, opts)
+			})
+		}
+	}
+	return c.sendChatCompletions(ctx, opts)
+}
+
+func (c *Client) sendChatCompletions(ctx context.Context, opts azopenai.ChatCompletionsOptions) (CompletionResult, error) {
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	defer release()
+	var resp azopenai.GetChatCompletionsResponse
+	var raw *http.Response
+	stats, err := c.withRetry(ctx, func() (err error) {
+		if c.cfg.Echo {
+			resp = echoResponse(opts.Messages)
+			return nil
+		}
+		resp, err = c.chat.GetChatCompletions(runtime.WithCaptureResponse(ctx, &raw), opts, nil)
+		return err
+	})
+	if err != nil {
+		return CompletionResult{Retry: stats}, c.wrapError(ctx, deref(opts.DeploymentName), err)
+	}
+	if len(resp.Choices) == 0 && !c.cfg.AllowEmptyChoices {
+		return CompletionResult{Retry: stats}, &ErrNoChoices{PromptFilterResults: resp.PromptFilterResults}
+	}
+	result := newCompletionResult(resp)
+	result.Retry = stats
+	if raw != nil && (len(result.Citations) == 0 || c.cfg.Debug) {
+		if body, err := runtime.Payload(raw); err == nil {
+			if shape := result.recoverCitations(body); shape != "" && c.cfg.Debug {
+				log.Printf("azurrr: response context matched shape %s", shape)
+			}
+		}
+	}
+	c.annotate(&result, opts)
+	limitToolCalls(&result, opts)
+	return result, nil
+}
+
+// limitToolCalls keeps only the first tool call when opts disable parallel
+// tool calls, which the service does not always enforce.
+func limitToolCalls(result *CompletionResult, opts azopenai.ChatCompletionsOptions) {
+	if opts.ParallelToolCalls != nil && !*opts.ParallelToolCalls && len(result.ToolCalls) > 1 {
+		result.ToolCalls = result.ToolCalls[:1]
+	}
+}
+
+func (c *Client) validate(messages []azopenai.ChatRequestMessageClassification) error {
+	for _, v := range c.validators {
+		if err := v(messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func retryOptionsSet(r policy.RetryOptions) bool {
+	return r.MaxRetries != 0 || r.TryTimeout != 0 || r.RetryDelay != 0 || r.MaxRetryDelay != 0 ||
+		r.StatusCodes != nil || r.ShouldRetry != nil
+}
+
+// maxApplicationIDLen is the length beyond which azcore silently truncates
+// the application ID.
+const maxApplicationIDLen = 24
+
+func applicationID(t *policy.TelemetryOptions) error {
+	if t.ApplicationID == "" {
+		t.ApplicationID = moduleName + "/" + moduleVersion
+		return nil
+	}
+	if len(t.ApplicationID) > maxApplicationIDLen {
+		return fmt.Errorf("azurrr: application ID %q is longer than %d characters", t.ApplicationID, maxApplicationIDLen)
+	}
+	if strings.ContainsAny(t.ApplicationID, " \t\r\n") {
+		return fmt.Errorf("azurrr: application ID %q must not contain whitespace", t.ApplicationID)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeClient returns a client whose calls are answered by h over TLS.
+// cfg needs no endpoint or key.
+func newFakeClient(t *testing.T, cfg Config, h http.HandlerFunc, options *ClientOptions) *Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(h)
+	t.Cleanup(srv.Close)
+	cfg.Endpoint = srv.URL
+	if cfg.APIKey == "" {
+		cfg.APIKey = "test-key"
+	}
+	if cfg.Deployment == "" {
+		cfg.Deployment = "gpt-4o"
+	}
+	if options == nil {
+		options = &ClientOptions{}
+	}
+	options.ClientOptions.Transport = srv.Client()
+	options.ClientOptions.Retry = policy.RetryOptions{MaxRetries: -1}
+	c, err := NewClient(cfg, options)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+// respondWith answers every request with resp as JSON.
+func respondWith(resp azopenai.GetChatCompletionsResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp.ChatCompletions)
+	}
+}
+
+// streamWith answers every request with chunks as a server-sent event
+// stream.
+func streamWith(chunks []azopenai.ChatCompletions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}
+}
+
+func userMessages(content string) []azopenai.ChatRequestMessageClassification {
+	return []azopenai.ChatRequestMessageClassification{
+		&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(content)},
+	}
+}
+
+func TestNoChoices(t *testing.T) {
+	resp := test.NewResponse().NoChoices().PromptFilter("violence").Build()
+
+	t.Run("error", func(t *testing.T) {
+		c := newFakeClient(t, Config{}, respondWith(resp), nil)
+		_, err := c.Complete(context.Background(), userMessages("hi"), Params{})
+		var noChoices *ErrNoChoices
+		if !errors.As(err, &noChoices) {
+			t.Fatalf("err = %v, want *ErrNoChoices", err)
+		}
+		if len(noChoices.PromptFilterResults) != 1 {
+			t.Errorf("PromptFilterResults = %d, want 1", len(noChoices.PromptFilterResults))
+		}
+	})
+
+	t.Run("AllowEmptyChoices", func(t *testing.T) {
+		c := newFakeClient(t, Config{AllowEmptyChoices: true}, respondWith(resp), nil)
+		result, err := c.Complete(context.Background(), userMessages("hi"), Params{})
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		if result.Content != "" || result.FinishReason != "" {
+			t.Errorf("result = %+v, want empty", result)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup runs at most one call per key at a time; callers arriving
+// while it is in flight wait for it and share its result. The key is
+// forgotten as soon as the call returns, so neither results nor errors
+// outlive it. Each waiter gives up when its own context ends, and a waiter
+// whose context is still live retries rather than take the first caller's
+// cancellation or deadline error.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done   chan struct{}
+	result CompletionResult
+	err    error
+}
+
+func newFlightGroup(enabled bool) *flightGroup {
+	if !enabled {
+		return nil
+	}
+	return &flightGroup{calls: map[string]*flight{}}
+}
+
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (CompletionResult, error)) (CompletionResult, error) {
+	for {
+		g.mu.Lock()
+		f, ok := g.calls[key]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return CompletionResult{}, ctx.Err()
+		case <-f.done:
+		}
+		if isContextError(f.err) && ctx.Err() == nil {
+			continue
+		}
+		result := f.result
+		result.Shared = true
+		return result, f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.result, f.err = fn(ctx)
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+	return f.result, f.err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,74 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupFollowerCancel(t *testing.T) {
+	g := newFlightGroup(true)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go g.do(context.Background(), "k", func(context.Context) (CompletionResult, error) {
+		close(started)
+		<-release
+		return CompletionResult{}, nil
+	})
+	defer close(release)
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error)
+	go func() {
+		_, err := g.do(ctx, "k", func(context.Context) (CompletionResult, error) {
+			t.Error("follower ran its own call")
+			return CompletionResult{}, nil
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled follower kept waiting for the leader")
+	}
+}
+
+func TestFlightGroupLeaderCancel(t *testing.T) {
+	g := newFlightGroup(true)
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leaderDone := make(chan error)
+	go func() {
+		_, err := g.do(leaderCtx, "k", func(ctx context.Context) (CompletionResult, error) {
+			close(started)
+			<-ctx.Done()
+			return CompletionResult{}, ctx.Err()
+		})
+		leaderDone <- err
+	}()
+	<-started
+	followerDone := make(chan struct{})
+	var result CompletionResult
+	var err error
+	go func() {
+		defer close(followerDone)
+		result, err = g.do(context.Background(), "k", func(context.Context) (CompletionResult, error) {
+			return CompletionResult{Content: "retried"}, nil
+		})
+	}()
+	// Let the follower start waiting on the leader before cancelling it.
+	time.Sleep(10 * time.Millisecond)
+	cancelLeader()
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader err = %v, want context.Canceled", err)
+	}
+	<-followerDone
+	if err != nil || result.Content != "retried" {
+		t.Errorf("follower = %q, %v, want its own retried call", result.Content, err)
+	}
+}
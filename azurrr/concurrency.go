@@ -0,0 +1,81 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens to a call when MaxConcurrent calls are
+// already in flight.
+type OverflowPolicy string
+
+const (
+	// OverflowQueue waits for a free slot or for the context to end.
+	OverflowQueue OverflowPolicy = "queue"
+	// OverflowReject fails immediately with ErrConcurrencyLimit.
+	OverflowReject OverflowPolicy = "reject"
+)
+
+// ErrConcurrencyLimit is returned under OverflowReject when the client is
+// already running MaxConcurrent calls.
+var ErrConcurrencyLimit = errors.New("azurrr: concurrent request limit reached")
+
+// ClientStats is a snapshot of the client's in-flight work.
+type ClientStats struct {
+	InFlight int
+	Waiting  int
+}
+
+// limiter caps in-flight completions. A nil sem means unlimited.
+type limiter struct {
+	sem      chan struct{}
+	policy   OverflowPolicy
+	inFlight atomic.Int64
+	waiting  atomic.Int64
+}
+
+func newLimiter(max int, policy OverflowPolicy) *limiter {
+	l := &limiter{policy: policy}
+	if max > 0 {
+		l.sem = make(chan struct{}, max)
+	}
+	return l
+}
+
+// acquire reserves a slot; the returned func releases it.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l.sem != nil {
+		if l.policy == OverflowReject {
+			select {
+			case l.sem <- struct{}{}:
+			default:
+				return nil, ErrConcurrencyLimit
+			}
+		} else {
+			l.waiting.Add(1)
+			select {
+			case l.sem <- struct{}{}:
+				l.waiting.Add(-1)
+			case <-ctx.Done():
+				l.waiting.Add(-1)
+				return nil, ctx.Err()
+			}
+		}
+	}
+	l.inFlight.Add(1)
+	return func() {
+		l.inFlight.Add(-1)
+		if l.sem != nil {
+			<-l.sem
+		}
+	}, nil
+}
+
+// Stats reports how many calls are running and how many are queued.
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		InFlight: int(c.limiter.inFlight.Load()),
+		Waiting:  int(c.limiter.waiting.Load()),
+	}
+}
@@ -0,0 +1,308 @@
+package azurrr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/kelseyhightower/envconfig"
+	"log"
+	"regexp"
+	"time"
+)
+
+// Config holds the settings needed to talk to Azure OpenAI and the search index
+// used for On Your Data grounding.
+type Config struct {
+	APIKey     string `envconfig:"AZURE_OPENAI_API_KEY" secret:"true"`
+	Endpoint   string `envconfig:"AOAI_ENDPOINT_URL"`
+	Deployment string `envconfig:"DEPLOYMENT_NAME"`
+
+	// SuggestDeployments lists the resource's deployments when a call names
+	// an unknown one, to suggest close matches in ErrDeploymentNotFound. The
+	// listing needs permission to read deployments.
+	SuggestDeployments bool `envconfig:"SUGGEST_DEPLOYMENTS" default:"false"`
+
+	// ResourceName and Region are an alternative to Endpoint: when Endpoint
+	// is empty it is built as https://{ResourceName}.openai.azure.com. Azure
+	// OpenAI hostnames are not region-qualified, so Region is informational.
+	// Setting both Endpoint and ResourceName is an error.
+	ResourceName string `envconfig:"AOAI_RESOURCE_NAME"`
+	Region       string `envconfig:"AOAI_REGION"`
+
+	SearchEndpoint string `envconfig:"SEARCH_ENDPOINT"`
+	SearchIndex    string `envconfig:"SEARCH_INDEX_NAME"`
+	SearchAPIKey   string `envconfig:"SEARCH_KEY" secret:"true"`
+	// SearchAuth is how On Your Data authenticates to the search service.
+	// The managed identity methods use the OpenAI resource's identity, with
+	// SearchManagedIdentityID naming a user-assigned one.
+	SearchAuth              AuthMethod `envconfig:"SEARCH_AUTH_TYPE" default:"api_key"`
+	SearchManagedIdentityID string     `envconfig:"SEARCH_MANAGED_IDENTITY_RESOURCE_ID"`
+	SearchAccessToken       string     `envconfig:"SEARCH_ACCESS_TOKEN" secret:"true"`
+	EmbeddingEndpoint       string     `envconfig:"EMBEDDING_ENDPOINT"`
+	// EmbeddingAPIVersion overrides the api-version query parameter of
+	// EmbeddingEndpoint when the embedding deployment needs a different
+	// version than the chat call.
+	EmbeddingAPIVersion string `envconfig:"EMBEDDING_API_VERSION"`
+	// EmbeddingAPIKey authenticates the embedding endpoint when it lives on
+	// a different resource than the chat deployment. Defaults to APIKey.
+	EmbeddingAPIKey string `envconfig:"EMBEDDING_API_KEY" secret:"true"`
+	// EmbeddingAuth is how On Your Data authenticates to EmbeddingEndpoint:
+	// AuthAPIKey or AuthAccessToken.
+	EmbeddingAuth        AuthMethod `envconfig:"EMBEDDING_AUTH_TYPE" default:"api_key"`
+	EmbeddingAccessToken string     `envconfig:"EMBEDDING_ACCESS_TOKEN" secret:"true"`
+	// EmbeddingDeployment is the deployment Client.Embed calls, and
+	// EmbeddingRequestsPerMinute opts into pacing those calls; zero leaves
+	// them unpaced.
+	EmbeddingDeployment        string `envconfig:"EMBEDDING_DEPLOYMENT_NAME"`
+	EmbeddingRequestsPerMinute int    `envconfig:"EMBEDDING_REQUESTS_PER_MINUTE" default:"0"`
+	// EmbeddingDimensions requests shorter vectors from models that support
+	// it; zero uses the model's size.
+	EmbeddingDimensions int `envconfig:"EMBEDDING_DIMENSIONS" default:"0"`
+	// EmbeddingCacheSize keeps up to this many embeddings in memory so Embed
+	// does not re-embed identical texts, each for EmbeddingCacheTTL if set.
+	// Zero disables the cache unless ClientOptions.EmbeddingCache is given.
+	EmbeddingCacheSize int           `envconfig:"EMBEDDING_CACHE_SIZE" default:"0"`
+	EmbeddingCacheTTL  time.Duration `envconfig:"EMBEDDING_CACHE_TTL" default:"0"`
+
+	SystemPrompt string `envconfig:"SYSTEM_PROMPT" default:"You are an AI assistant that helps people find information"`
+	// RoleInformation describes the assistant's persona and limits, and
+	// FallbackMessage is the exact reply it should give when the index has
+	// no answer. Both are added to the system prompt, as the current On Your
+	// Data API has no parameters for them. A reply matching FallbackMessage
+	// sets CompletionResult.Fallback and Refused.
+	RoleInformation string `envconfig:"SEARCH_ROLE_INFORMATION"`
+	FallbackMessage string `envconfig:"SEARCH_FALLBACK_MESSAGE"`
+	// DeveloperPrompt is sent as a developer message after the system prompt
+	// by Ask and Session. DeveloperRole confirms the deployment supports
+	// that role; without it developer messages are sent as system messages.
+	DeveloperPrompt string `envconfig:"DEVELOPER_PROMPT"`
+	DeveloperRole   bool   `envconfig:"DEPLOYMENT_SUPPORTS_DEVELOPER_ROLE" default:"false"`
+	// PromptTimezone, an IANA name such as "Europe/Berlin", opts into
+	// appending the current local date and time to the system prompt so
+	// relative questions are not answered in UTC or training-cutoff time.
+	PromptTimezone string `envconfig:"PROMPT_TIMEZONE"`
+	// ScopeExamples are sample in-scope exchanges sent before the user's
+	// question to prime refusal behavior. They must alternate user and
+	// assistant turns, starting with user. Set in code; not read from env.
+	ScopeExamples []Turn `ignored:"true"`
+
+	SearchQueryType       string `envconfig:"SEARCH_QUERY_TYPE" default:"vector_simple_hybrid"`
+	SemanticConfiguration string `envconfig:"SEARCH_SEMANTIC_CONFIGURATION" default:"azureml-default"`
+	Strictness            int32  `envconfig:"SEARCH_STRICTNESS" default:"5"`
+	TopNDocuments         int32  `envconfig:"SEARCH_TOP_N_DOCUMENTS" default:"5"`
+	InScope               bool   `envconfig:"SEARCH_IN_SCOPE" default:"true"`
+	FilePathField         string `envconfig:"SEARCH_FILEPATH_FIELD" default:"filepath"`
+	// MinCitations flags grounded answers citing fewer sources as
+	// LowConfidence. With LowConfidenceTopNDocuments above TopNDocuments, a
+	// non-streaming call is first retried once retrieving that many
+	// documents.
+	MinCitations               int   `envconfig:"SEARCH_MIN_CITATIONS" default:"0"`
+	LowConfidenceTopNDocuments int32 `envconfig:"SEARCH_LOW_CONFIDENCE_TOP_N_DOCUMENTS" default:"0"`
+	// SearchFilter is an OData filter applied to every search, e.g.
+	// "tenant eq 'contoso'", so grounding never crosses tenants or
+	// categories. Params.Filter overrides it per call.
+	SearchFilter string `envconfig:"SEARCH_FILTER"`
+	// SearchAllowPartialResult lets an answer be generated when some of the
+	// search queries fail or time out, instead of failing the request.
+	SearchAllowPartialResult bool `envconfig:"SEARCH_ALLOW_PARTIAL_RESULT" default:"false"`
+
+	// EnhanceGrounding and EnhanceOCR request the Azure vision enhancements,
+	// which only vision-capable deployments support; set DeploymentVision
+	// to confirm the deployment is one.
+	EnhanceGrounding bool `envconfig:"ENHANCEMENT_GROUNDING" default:"false"`
+	EnhanceOCR       bool `envconfig:"ENHANCEMENT_OCR" default:"false"`
+	DeploymentVision bool `envconfig:"DEPLOYMENT_SUPPORTS_VISION" default:"false"`
+
+	// MaxTokens is the global default used when neither the call nor
+	// DeploymentMaxTokens specify a limit. Zero uses DefaultMaxTokens; a
+	// negative value sends no limit.
+	MaxTokens int32 `envconfig:"MAX_TOKENS"`
+	// DeploymentMaxTokens maps a deployment name to its default MaxTokens,
+	// e.g. DEPLOYMENT_MAX_TOKENS="gpt-4o:4096,gpt-35-turbo:800".
+	DeploymentMaxTokens map[string]int32 `envconfig:"DEPLOYMENT_MAX_TOKENS"`
+	// DeploymentContextTokens maps a deployment name to its context window,
+	// e.g. DEPLOYMENT_CONTEXT_TOKENS="gpt-4o:128000", so MaxTokens can be
+	// checked against the estimated prompt before calling Azure.
+	// ClampMaxTokens lowers MaxTokens to fit instead of failing with
+	// *ErrMaxTokensTooLarge.
+	DeploymentContextTokens map[string]int32 `envconfig:"DEPLOYMENT_CONTEXT_TOKENS"`
+	ClampMaxTokens          bool             `envconfig:"CLAMP_MAX_TOKENS" default:"false"`
+
+	// MaxContinuations is how many times a non-streaming answer that stops
+	// at MaxTokens is continued by sending it back and asking the model to
+	// go on. Each continuation is a full call. Zero disables it.
+	MaxContinuations int `envconfig:"MAX_CONTINUATIONS" default:"0"`
+
+	// MaxRetries is how many times a throttled or failed call is retried on
+	// top of the SDK pipeline's own retries. Zero disables it.
+	MaxRetries    int           `envconfig:"MAX_RETRIES" default:"0"`
+	RetryDelay    time.Duration `envconfig:"RETRY_DELAY" default:"1s"`
+	MaxRetryDelay time.Duration `envconfig:"RETRY_MAX_DELAY" default:"30s"`
+
+	// MaxConcurrent caps in-flight completions per client; zero is unlimited.
+	// OverflowPolicy chooses between queueing and rejecting excess calls.
+	MaxConcurrent  int            `envconfig:"MAX_CONCURRENT_REQUESTS" default:"0"`
+	OverflowPolicy OverflowPolicy `envconfig:"CONCURRENCY_OVERFLOW_POLICY" default:"queue"`
+
+	// CoalesceRequests makes identical concurrent non-streaming calls, as
+	// keyed by HashRequest, share one Azure request and its result. Callers
+	// must not modify the slices of a shared result.
+	CoalesceRequests bool `envconfig:"COALESCE_REQUESTS" default:"false"`
+
+	// StoreCompletions asks the service to keep completions for later
+	// retrieval with Client.StoredCompletion, for evals and datasets. The
+	// field needs api-version 2025-02-01-preview, so chat requests are sent
+	// with that version while it is set, newer than the one the SDK pins.
+	StoreCompletions bool `envconfig:"STORE_COMPLETIONS" default:"false"`
+
+	// MaxToolRounds bounds how many rounds of tool calls RunTools executes
+	// before giving up with *ErrToolLoopExceeded. Zero means 5.
+	MaxToolRounds int `envconfig:"MAX_TOOL_ROUNDS" default:"5"`
+
+	// AllowEmptyChoices makes a response without choices an empty successful
+	// result instead of an *ErrNoChoices error.
+	AllowEmptyChoices bool `envconfig:"ALLOW_EMPTY_CHOICES" default:"false"`
+
+	// LogRequests logs a summary of every outgoing call. TraceContent adds
+	// the message text to those logs and to trace spans; it is off by
+	// default because system prompts often contain proprietary instructions.
+	LogRequests  bool `envconfig:"LOG_REQUESTS" default:"false"`
+	TraceContent bool `envconfig:"TRACE_MESSAGE_CONTENT" default:"false"`
+
+	// Debug logs diagnostics meant for troubleshooting rather than for
+	// production logs, such as which context shape a response's citations
+	// were read from.
+	Debug bool `envconfig:"AZURRR_DEBUG" default:"false"`
+
+	// Echo selects the offline echo deployment for local development: chat
+	// calls never reach Azure and answer with the last user message, through
+	// the usual result parsing. No key or endpoint is needed. It must be
+	// set explicitly and is logged loudly so it cannot slip into production.
+	Echo bool `envconfig:"AZURRR_ECHO" default:"false"`
+}
+
+// LoadConfig reads the configuration from the environment.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	if err := envconfig.Process("", &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// checkScopeExamples enforces the user/assistant alternation of
+// ScopeExamples.
+func (c Config) checkScopeExamples() error {
+	if len(c.ScopeExamples)%2 != 0 {
+		return fmt.Errorf("azurrr: ScopeExamples must hold complete user/assistant pairs, got %d turns", len(c.ScopeExamples))
+	}
+	for i, t := range c.ScopeExamples {
+		want := RoleUser
+		if i%2 == 1 {
+			want = RoleAssistant
+		}
+		if ChatRole(t.Role) != want {
+			return fmt.Errorf("azurrr: ScopeExamples[%d] has role %q, want %q", i, t.Role, want)
+		}
+	}
+	return nil
+}
+
+// enhancements returns the configured enhancements, or nil when none are
+// enabled so the request omits the field.
+func (c Config) enhancements() (*azopenai.AzureChatEnhancementConfiguration, error) {
+	if !c.EnhanceGrounding && !c.EnhanceOCR {
+		return nil, nil
+	}
+	if !c.DeploymentVision {
+		return nil, fmt.Errorf("azurrr: ENHANCEMENT_GROUNDING and ENHANCEMENT_OCR need a vision-capable deployment; set DEPLOYMENT_SUPPORTS_VISION if %q is one", c.Deployment)
+	}
+	e := &azopenai.AzureChatEnhancementConfiguration{}
+	if c.EnhanceGrounding {
+		e.Grounding = &azopenai.AzureChatGroundingEnhancementConfiguration{Enabled: to.Ptr(true)}
+	}
+	if c.EnhanceOCR {
+		e.Ocr = &azopenai.AzureChatOCREnhancementConfiguration{Enabled: to.Ptr(true)}
+	}
+	return e, nil
+}
+
+// resourceNamePattern follows the Azure custom subdomain rules: 2-64
+// alphanumerics or hyphens, not starting or ending with a hyphen.
+var resourceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]{0,62}[a-zA-Z0-9]$`)
+
+// endpoint returns the explicit Endpoint if set, otherwise the one derived
+// from ResourceName.
+func (c Config) endpoint() (string, error) {
+	if c.Echo && c.Endpoint == "" && c.ResourceName == "" {
+		return echoEndpoint, nil
+	}
+	if c.Endpoint != "" || c.ResourceName == "" {
+		return c.Endpoint, nil
+	}
+	if !resourceNamePattern.MatchString(c.ResourceName) {
+		return "", fmt.Errorf("azurrr: invalid resource name %q", c.ResourceName)
+	}
+	return fmt.Sprintf("https://%s.openai.azure.com", c.ResourceName), nil
+}
+
+// maxTokens resolves the MaxTokens to send for deployment: the per-call value
+// wins, then the deployment default, then the global default, which falls
+// back to DefaultMaxTokens when unset. It is nil, sending no limit, when the
+// global default is negative; the service rejects max_tokens 0.
+func (c Config) maxTokens(deployment string, p Params) *int32 {
+	if p.MaxTokens != nil {
+		return p.MaxTokens
+	}
+	if n, ok := c.DeploymentMaxTokens[deployment]; ok {
+		return &n
+	}
+	switch {
+	case c.MaxTokens < 0:
+		return nil
+	case c.MaxTokens == 0:
+		n := DefaultMaxTokens
+		return &n
+	}
+	return &c.MaxTokens
+}
+
+func (c Config) embeddingKey() string {
+	if c.EmbeddingAPIKey != "" {
+		return c.EmbeddingAPIKey
+	}
+	return c.APIKey
+}
+
+// isVectorQueryType reports whether t needs the query to be embedded.
+func isVectorQueryType(t azopenai.AzureSearchQueryType) bool {
+	switch t {
+	case azopenai.AzureSearchQueryTypeVector, azopenai.AzureSearchQueryTypeVectorSimpleHybrid, azopenai.AzureSearchQueryTypeVectorSemanticHybrid:
+		return true
+	}
+	return false
+}
+
+// checkEmbedding fails fast when a vector query type has no embedding source
+// to vectorize the query with, which Azure otherwise reports confusingly.
+func (c Config) checkEmbedding() error {
+	if c.SearchEndpoint == "" {
+		return nil
+	}
+	vector := isVectorQueryType(azopenai.AzureSearchQueryType(c.SearchQueryType))
+	if vector && c.EmbeddingEndpoint == "" {
+		return fmt.Errorf("azurrr: query type %q needs an embedding source; set EMBEDDING_ENDPOINT or use a non-vector SEARCH_QUERY_TYPE", c.SearchQueryType)
+	}
+	if c.EmbeddingEndpoint != "" && c.EmbeddingAuth != AuthAccessToken && c.embeddingKey() == "" {
+		return errors.New("azurrr: EMBEDDING_ENDPOINT is set but neither EMBEDDING_API_KEY nor AZURE_OPENAI_API_KEY is")
+	}
+	return nil
+}
+
+// warnUnusedEmbedding logs an embedding source that will never be used.
+func (c Config) warnUnusedEmbedding() {
+	if c.SearchEndpoint != "" && c.EmbeddingEndpoint != "" && !isVectorQueryType(azopenai.AzureSearchQueryType(c.SearchQueryType)) {
+		log.Printf("azurrr: EMBEDDING_ENDPOINT is set but query type %q does not use it", c.SearchQueryType)
+	}
+}
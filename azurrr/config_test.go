@@ -0,0 +1,31 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"testing"
+)
+
+func TestMaxTokens(t *testing.T) {
+	cfg := Config{MaxTokens: 800, DeploymentMaxTokens: map[string]int32{"gpt-4o": 4096}}
+	tests := []struct {
+		name       string
+		cfg        Config
+		deployment string
+		p          Params
+		want       *int32
+	}{
+		{"per call wins", cfg, "gpt-4o", Params{MaxTokens: to.Ptr[int32](10)}, to.Ptr[int32](10)},
+		{"deployment default", cfg, "gpt-4o", Params{}, to.Ptr[int32](4096)},
+		{"global default", cfg, "gpt-35-turbo", Params{}, to.Ptr[int32](800)},
+		{"zero config uses the package default", Config{}, "gpt-4o", Params{}, &DefaultMaxTokens},
+		{"negative sends no limit", Config{MaxTokens: -1}, "gpt-4o", Params{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.maxTokens(tt.deployment, tt.p)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("maxTokens = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ConfigFromFile loads a JSON or YAML file (chosen by extension) whose keys
+// are the same names as the environment variables, e.g.
+//
+//	AOAI_ENDPOINT_URL: https://my-resource.openai.azure.com
+//	DEPLOYMENT_NAME: gpt-4o
+//	MAX_RETRIES: 3
+//
+// Environment variables that are set take precedence over the file. The
+// merged configuration is checked like Validate, except that a missing
+// APIKey is left for NewClient to report, since the client may be given a
+// token credential instead.
+func ConfigFromFile(path string) (Config, error) {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("envconfig")
+		if key == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			return Config{}, fmt.Errorf("azurrr: %s: %s: %w", path, key, err)
+		}
+	}
+	if err := cfg.validate(false); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// readConfigFile returns the file's top-level values keyed by upper-cased
+// name, each re-encoded as JSON so both formats decode the same way.
+func readConfigFile(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var parsed map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &parsed)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &parsed)
+	default:
+		return nil, fmt.Errorf("azurrr: unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("azurrr: %s: %w", path, err)
+	}
+	values := make(map[string]json.RawMessage, len(parsed))
+	for k, val := range parsed {
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return nil, fmt.Errorf("azurrr: %s: %s: %w", path, k, err)
+		}
+		values[strings.ToUpper(k)] = raw
+	}
+	return values, nil
+}
+
+func setField(f reflect.Value, raw json.RawMessage) error {
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			f.SetInt(int64(d))
+			return nil
+		}
+	}
+	return json.Unmarshal(raw, f.Addr().Interface())
+}
+
+// validateRequired reports the settings no call can be made without. The
+// API key is not needed when a token credential is used instead.
+func (c Config) validateRequired(needKey bool) error {
+	if c.Echo {
+		return nil
+	}
+	var missing []string
+	if needKey && c.APIKey == "" {
+		missing = append(missing, "AZURE_OPENAI_API_KEY")
+	}
+	if c.Endpoint == "" && c.ResourceName == "" {
+		missing = append(missing, "AOAI_ENDPOINT_URL or AOAI_RESOURCE_NAME")
+	}
+	if c.Deployment == "" {
+		missing = append(missing, "DEPLOYMENT_NAME")
+	}
+	if len(missing) > 0 {
+		return errors.New("azurrr: missing required config: " + strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package azurrr
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// contextShape names a layout Azure has used for the On Your Data context
+// of a response.
+type contextShape string
+
+const (
+	// shapeContext is message.context.citations, from 2024-02-15-preview.
+	shapeContext contextShape = "message.context.citations"
+	// shapeToolMessage is a tool message in message.context.messages whose
+	// content is the JSON of the citations, from 2023-08-01-preview to
+	// 2023-12-01-preview.
+	shapeToolMessage contextShape = "message.context.messages"
+	// shapeChoiceMessages is the same tool message in choice.messages next
+	// to the answer, from 2023-06-01-preview.
+	shapeChoiceMessages contextShape = "choice.messages"
+)
+
+type wireCitation struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	FilePath string `json:"filepath"`
+	ChunkID  string `json:"chunk_id"`
+	Content  string `json:"content"`
+}
+
+type wireToolMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// contextCitations reads the citations of the first choice in a raw
+// response body, trying each known shape from newest to oldest. It returns
+// no citations and no shape when none matches; a response without context
+// is not an error.
+func contextCitations(body []byte) ([]Citation, contextShape) {
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Context struct {
+					Citations []wireCitation    `json:"citations"`
+					Messages  []wireToolMessage `json:"messages"`
+				} `json:"context"`
+			} `json:"message"`
+			Messages []wireToolMessage `json:"messages"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Choices) == 0 {
+		return nil, ""
+	}
+	choice := resp.Choices[0]
+	if cites := choice.Message.Context.Citations; len(cites) > 0 {
+		return fromWire(cites), shapeContext
+	}
+	if cites := toolCitations(choice.Message.Context.Messages); len(cites) > 0 {
+		return cites, shapeToolMessage
+	}
+	if cites := toolCitations(choice.Messages); len(cites) > 0 {
+		return cites, shapeChoiceMessages
+	}
+	return nil, ""
+}
+
+// toolCitations decodes the citations carried as JSON in a tool message.
+func toolCitations(messages []wireToolMessage) []Citation {
+	for _, m := range messages {
+		if m.Role != "tool" {
+			continue
+		}
+		var content struct {
+			Citations []wireCitation `json:"citations"`
+		}
+		if json.Unmarshal([]byte(m.Content), &content) == nil && len(content.Citations) > 0 {
+			return fromWire(content.Citations)
+		}
+	}
+	return nil
+}
+
+// recoverCitations fills in citations the SDK did not parse, for a response
+// in a context shape it does not know, and reports the shape that matched.
+// Only non-streaming responses are checked; stream chunks are read by the
+// SDK alone. A body that never mentions citations is not parsed again.
+func (r *CompletionResult) recoverCitations(body []byte) contextShape {
+	if len(r.Citations) > 0 {
+		return shapeContext
+	}
+	if !bytes.Contains(body, []byte("citations")) {
+		return ""
+	}
+	cites, shape := contextCitations(body)
+	if len(cites) > 0 {
+		r.Citations = cites
+		r.Refused = false
+		r.classifyGrounding("")
+	}
+	return shape
+}
+
+func fromWire(cites []wireCitation) []Citation {
+	out := make([]Citation, len(cites))
+	for i, c := range cites {
+		out[i] = Citation{Title: c.Title, URL: c.URL, FilePath: c.FilePath, ChunkID: c.ChunkID, Content: c.Content}
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package azurrr
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// toolMessageBody is a 2023-08-01-preview response whose citations sit in a
+// tool message the SDK does not parse.
+const toolMessageBody = `{"id":"1","created":0,"choices":[{"index":0,"finish_reason":"stop",` +
+	`"message":{"role":"assistant","content":"Paris [doc1].","context":{"messages":[` +
+	`{"role":"tool","content":"{\"citations\":[{\"title\":\"France\",\"url\":\"https://example.com/fr\"}]}"}]}}}]}`
+
+func TestRecoverCitationsDebugLog(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	for _, debug := range []bool{false, true} {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+
+		c := newFakeClient(t, Config{Debug: debug}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(toolMessageBody))
+		}, nil)
+		result, err := c.Complete(context.Background(), userMessages("capital of France?"), Params{})
+		if err != nil {
+			t.Fatalf("debug=%v: Complete: %v", debug, err)
+		}
+		if len(result.Citations) != 1 || result.Citations[0].Title != "France" {
+			t.Errorf("debug=%v: citations = %+v, want the tool message citation", debug, result.Citations)
+		}
+		logged := strings.Contains(logs.String(), string(shapeToolMessage))
+		if logged != debug {
+			t.Errorf("debug=%v: shape logged = %v, log:\n%s", debug, logged, logs.String())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package azurrr
+
+import (
+	"context"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// continuePrompt asks the model to resume an answer that was cut off.
+const continuePrompt = "Your previous answer was cut off. Continue it exactly where it stopped, without repeating any of it."
+
+// continuationMessages re-sends messages with the partial answer so the
+// next request picks up where a failed stream or a truncated answer
+// stopped.
+func continuationMessages(messages []azopenai.ChatRequestMessageClassification, partial string) []azopenai.ChatRequestMessageClassification {
+	if partial == "" {
+		return messages
+	}
+	return append(messages[:len(messages):len(messages)],
+		&azopenai.ChatRequestAssistantMessage{Content: azopenai.NewChatRequestAssistantMessageContent(partial)},
+		&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(continuePrompt)},
+	)
+}
+
+// appendResult joins the answer of a continuation request onto the result
+// it continues: content is concatenated and usage and retries are summed,
+// while the finish reason and the rest come from next.
+func appendResult(prev, next CompletionResult) CompletionResult {
+	merged := next
+	merged.Content = prev.Content + next.Content
+	merged.Retry.Retries += prev.Retry.Retries
+	merged.Retry.Wait += prev.Retry.Wait
+	merged.Usage.PromptTokens += prev.Usage.PromptTokens
+	merged.Usage.CompletionTokens += prev.Usage.CompletionTokens
+	merged.Usage.TotalTokens += prev.Usage.TotalTokens
+	merged.Continuations = prev.Continuations
+	if merged.Role == "" {
+		merged.Role = prev.Role
+	}
+	if merged.ID == "" {
+		merged.ID, merged.Model, merged.Created = prev.ID, prev.Model, prev.Created
+	}
+	if len(merged.Citations) == 0 {
+		merged.Citations = prev.Citations
+		merged.RetrievedDocuments = prev.RetrievedDocuments
+		merged.SearchQueries = prev.SearchQueries
+	}
+	merged.Grounded = len(merged.Citations) > 0
+	merged.Refused = !merged.Grounded && (prev.Refused || next.Refused)
+	return merged
+}
+
+// sendContinued makes the call and, while the answer stops at the MaxTokens
+// limit, asks for up to Config.MaxContinuations continuations, stitching
+// them into one answer.
+func (c *Client) sendContinued(ctx context.Context, opts azopenai.ChatCompletionsOptions) (CompletionResult, error) {
+	result, err := c.send(ctx, opts)
+	for err == nil && result.Continuations < c.cfg.MaxContinuations &&
+		result.FinishReason == string(azopenai.CompletionsFinishReasonTokenLimitReached) && len(result.ToolCalls) == 0 {
+		cont := opts
+		cont.Messages = continuationMessages(opts.Messages, result.Content)
+		next, nextErr := c.send(ctx, cont)
+		if nextErr != nil {
+			// Keep the truncated answer rather than losing it.
+			return result, nil
+		}
+		result = appendResult(result, next)
+		result.Continuations++
+	}
+	return result, err
+}
@@ -0,0 +1,24 @@
+package azurrr
+
+import (
+	"context"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// CredentialProvider supplies Microsoft Entra ID tokens for the client. It
+// has the shape of azcore.TokenCredential, so any azidentity credential can
+// be used directly. The SDK's bearer token policy caches the token and asks
+// the provider for a new one shortly before it expires, so a provider only
+// has to return a currently valid token, e.g. from workload identity
+// federation.
+type CredentialProvider interface {
+	GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error)
+}
+
+// CredentialFunc adapts a function to CredentialProvider.
+type CredentialFunc func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error)
+
+func (f CredentialFunc) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return f(ctx, options)
+}
@@ -0,0 +1,54 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCredentialRefresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		lifetime  time.Duration
+		wantCalls int32
+	}{
+		// A token that has expired by the second request must be fetched
+		// again. Tokens close to expiry are refreshed eagerly too, but at
+		// most every 30 seconds, too slowly to test here.
+		{"expired", 0, 2},
+		{"long lived", time.Hour, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			cred := CredentialFunc(func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				n := calls.Add(1)
+				return azcore.AccessToken{Token: fmt.Sprintf("token-%d", n), ExpiresOn: time.Now().Add(tt.lifetime)}, nil
+			})
+			var auth []string
+			reply := respondWith(test.NewResponse().Content("ok").Build())
+			c := newFakeClient(t, Config{MaxTokens: 10}, func(w http.ResponseWriter, r *http.Request) {
+				auth = append(auth, r.Header.Get("Authorization"))
+				reply(w, r)
+			}, &ClientOptions{Credential: cred})
+			for i := 0; i < 2; i++ {
+				if _, err := c.Complete(context.Background(), userMessages("hi"), Params{}); err != nil {
+					t.Fatalf("request %d: %v", i+1, err)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("GetToken called %d times, want %d", got, tt.wantCalls)
+			}
+			want := fmt.Sprintf("Bearer token-%d", tt.wantCalls)
+			if len(auth) != 2 || auth[0] != "Bearer token-1" || auth[1] != want {
+				t.Errorf("Authorization headers = %q, want token-1 then %s", auth, want)
+			}
+		})
+	}
+}
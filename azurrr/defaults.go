@@ -0,0 +1,49 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// The package defaults for nil Params fields, applied the same way by every
+// entrypoint. Override them at start-up, before any call is made; they are
+// not safe to change concurrently with calls. DefaultMaxTokens is used only
+// when neither Config.MaxTokens nor Config.DeploymentMaxTokens set a limit.
+var (
+	DefaultMaxTokens        int32   = 800
+	DefaultTemperature      float32 = 0.7
+	DefaultTopP             float32 = 0.95
+	DefaultFrequencyPenalty float32 = 0
+	DefaultPresencePenalty  float32 = 0
+)
+
+// applyDefaults fills p's nil sampling fields from the package defaults.
+func applyDefaults(p Params) Params {
+	if p.Temperature == nil {
+		p.Temperature = to.Ptr(DefaultTemperature)
+	}
+	if p.TopP == nil {
+		p.TopP = to.Ptr(DefaultTopP)
+	}
+	if p.FrequencyPenalty == nil {
+		p.FrequencyPenalty = to.Ptr(DefaultFrequencyPenalty)
+	}
+	if p.PresencePenalty == nil {
+		p.PresencePenalty = to.Ptr(DefaultPresencePenalty)
+	}
+	return p
+}
+
+// applyRawDefaults fills the same defaults into prebuilt options, leaving
+// the sampling alone when either Temperature or TopP is already chosen.
+func applyRawDefaults(opts *azopenai.ChatCompletionsOptions) {
+	if opts.Temperature == nil && opts.TopP == nil {
+		opts.Temperature = to.Ptr(DefaultTemperature)
+	}
+	if opts.FrequencyPenalty == nil {
+		opts.FrequencyPenalty = to.Ptr(DefaultFrequencyPenalty)
+	}
+	if opts.PresencePenalty == nil {
+		opts.PresencePenalty = to.Ptr(DefaultPresencePenalty)
+	}
+}
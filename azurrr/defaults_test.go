@@ -0,0 +1,115 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"net/http"
+	"testing"
+)
+
+// entrypoints call each public way of sending a chat request with no
+// options of their own.
+var entrypoints = map[string]func(ctx context.Context, c *Client) error{
+	"Complete": func(ctx context.Context, c *Client) error {
+		_, err := c.Complete(ctx, userMessages("hi"), Params{})
+		return err
+	},
+	"Ask": func(ctx context.Context, c *Client) error {
+		_, err := c.Ask(ctx, "hi", Params{})
+		return err
+	},
+	"Raw": func(ctx context.Context, c *Client) error {
+		_, err := c.Raw(ctx, azopenai.ChatCompletionsOptions{Messages: userMessages("hi")})
+		return err
+	},
+	"Stream": func(ctx context.Context, c *Client) error {
+		_, err := c.Stream(ctx, userMessages("hi"), Params{}, nil)
+		return err
+	},
+	"Session": func(ctx context.Context, c *Client) error {
+		_, err := c.NewSession(nil).Send(ctx, "hi")
+		return err
+	},
+	"StartAzure": func(ctx context.Context, c *Client) error {
+		return c.startAzure(ctx)
+	},
+}
+
+func TestDefaultsApplyToEveryEntrypoint(t *testing.T) {
+	resp := test.NewResponse().Content("ok").Build()
+	chunks := test.NewResponse().Content("ok").Stream()
+	for name, call := range entrypoints {
+		t.Run(name, func(t *testing.T) {
+			var body map[string]any
+			c := newFakeClient(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				if body["stream"] == true {
+					streamWith(chunks)(w, r)
+				} else {
+					respondWith(resp)(w, r)
+				}
+			}, nil)
+			if err := call(context.Background(), c); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			want := map[string]float64{
+				"max_tokens":        float64(DefaultMaxTokens),
+				"temperature":       float64(DefaultTemperature),
+				"frequency_penalty": float64(DefaultFrequencyPenalty),
+				"presence_penalty":  float64(DefaultPresencePenalty),
+			}
+			for field, v := range want {
+				got, ok := body[field].(float64)
+				if !ok || float32(got) != float32(v) {
+					t.Errorf("%s = %v, want %v", field, body[field], v)
+				}
+			}
+			if _, ok := body["top_p"]; ok {
+				t.Errorf("top_p sent alongside temperature")
+			}
+		})
+	}
+}
+
+func TestTokenBudgetAppliesToEveryEntrypoint(t *testing.T) {
+	for name, call := range entrypoints {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{MaxTokens: 100, DeploymentContextTokens: map[string]int32{"gpt-4o": 5}}
+			c := newFakeClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+				t.Error("request sent despite exceeding the token budget")
+			}, nil)
+			var tooLarge *ErrMaxTokensTooLarge
+			if err := call(context.Background(), c); !errors.As(err, &tooLarge) {
+				t.Fatalf("err = %v, want *ErrMaxTokensTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestRawKeepsExplicitSettings(t *testing.T) {
+	var body map[string]any
+	c := newFakeClient(t, Config{MaxTokens: 100}, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		respondWith(test.NewResponse().Content("ok").Build())(w, r)
+	}, nil)
+	_, err := c.Raw(context.Background(), azopenai.ChatCompletionsOptions{
+		Messages:  userMessages("hi"),
+		MaxTokens: to.Ptr[int32](7),
+		TopP:      to.Ptr[float32](0.5),
+	})
+	if err != nil {
+		t.Fatalf("Raw: %v", err)
+	}
+	if body["max_tokens"] != 7.0 || body["top_p"] != 0.5 {
+		t.Errorf("max_tokens = %v, top_p = %v, want 7 and 0.5", body["max_tokens"], body["top_p"])
+	}
+	if _, ok := body["temperature"]; ok {
+		t.Errorf("temperature defaulted although TopP was set")
+	}
+}
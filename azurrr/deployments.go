@@ -0,0 +1,112 @@
+package azurrr
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// deploymentsAPIVersion is the last data plane api-version that lists
+// deployments.
+const deploymentsAPIVersion = "2022-12-01"
+
+// maxSuggestions caps ErrDeploymentNotFound.Suggestions.
+const maxSuggestions = 3
+
+// suggestDeployments returns the deployments whose names are close to
+// attempted, nearest first. It only lists deployments under
+// SUGGEST_DEPLOYMENTS, and treats a failed listing as no suggestions, since
+// it only decorates an error already being returned.
+func (c *Client) suggestDeployments(ctx context.Context, attempted string) []string {
+	if !c.cfg.SuggestDeployments {
+		return nil
+	}
+	names, err := c.listDeployments(ctx)
+	if err != nil {
+		log.Printf("azurrr: listing deployments for suggestions: %v", err)
+		return nil
+	}
+	return closestNames(attempted, names)
+}
+
+func (c *Client) listDeployments(ctx context.Context) ([]string, error) {
+	endpoint, err := c.cfg.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	req, err := runtime.NewRequest(ctx, http.MethodGet, strings.TrimRight(endpoint, "/")+"/openai/deployments")
+	if err != nil {
+		return nil, err
+	}
+	req.Raw().URL.RawQuery = url.Values{"api-version": {deploymentsAPIVersion}}.Encode()
+	resp, err := c.rest.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing deployments: %s", resp.Status)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &list); err != nil {
+		return nil, fmt.Errorf("decoding deployments: %w", err)
+	}
+	names := make([]string, 0, len(list.Data))
+	for _, d := range list.Data {
+		names = append(names, d.ID)
+	}
+	return names, nil
+}
+
+// closestNames ranks names by edit distance to target, ignoring case, and
+// keeps those within a third of the target's length, or 2 for short names.
+func closestNames(target string, names []string) []string {
+	target = strings.ToLower(target)
+	limit := max(2, len(target)/3)
+	type match struct {
+		name string
+		dist int
+	}
+	var matches []match
+	for _, n := range names {
+		if d := editDistance(target, strings.ToLower(n)); d <= limit {
+			matches = append(matches, match{n, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].dist < matches[j].dist })
+	var out []string
+	for _, m := range matches[:min(len(matches), maxSuggestions)] {
+		out = append(out, m.name)
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,57 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestErrDeploymentNotFoundMessage(t *testing.T) {
+	tests := []struct {
+		suggestions []string
+		want        string
+	}{
+		{nil, `azurrr: deployment "gpt4o" not found`},
+		{[]string{"gpt-4o"}, `azurrr: deployment "gpt4o" not found; did you mean "gpt-4o"?`},
+		{[]string{"gpt-4o", "gpt-4"}, `azurrr: deployment "gpt4o" not found; did you mean "gpt-4o", "gpt-4"?`},
+	}
+	for _, tt := range tests {
+		err := &ErrDeploymentNotFound{Attempted: "gpt4o", Suggestions: tt.suggestions}
+		if got := err.Error(); got != tt.want {
+			t.Errorf("Error() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestClosestNames(t *testing.T) {
+	names := []string{"embed-large", "gpt-4o-mini", "gpt-4o", "GPT-4", "o1"}
+	if got, want := closestNames("gpt4o", names), []string{"gpt-4o", "GPT-4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("closestNames = %q, want %q", got, want)
+	}
+	if got := closestNames("whisper", names); got != nil {
+		t.Errorf("closestNames = %q, want none", got)
+	}
+}
+
+func TestDeploymentNotFoundSuggestions(t *testing.T) {
+	c := newFakeClient(t, Config{Deployment: "gpt4o", MaxTokens: 10, SuggestDeployments: true}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/openai/deployments" {
+			fmt.Fprint(w, `{"data":[{"id":"gpt-4o"},{"id":"embed-large"}]}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`)
+	}, nil)
+	_, err := c.Complete(context.Background(), userMessages("hi"), Params{})
+	var notFound *ErrDeploymentNotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("err = %v, want *ErrDeploymentNotFound", err)
+	}
+	if notFound.Attempted != "gpt4o" || !reflect.DeepEqual(notFound.Suggestions, []string{"gpt-4o"}) {
+		t.Errorf("err = %+v", notFound)
+	}
+}
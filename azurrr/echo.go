@@ -0,0 +1,83 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"io"
+	"strings"
+)
+
+// echoEndpoint stands in for the endpoint in echo mode, which never
+// connects to it.
+const echoEndpoint = "https://echo.invalid"
+
+// echoContent is the canned answer: the last user message, prefixed so it
+// cannot be mistaken for a model reply.
+func echoContent(messages []azopenai.ChatRequestMessageClassification) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if role, text := messageText(messages[i]); ChatRole(role) == RoleUser {
+			return "echo: " + text
+		}
+	}
+	return "echo:"
+}
+
+func echoUsage(messages []azopenai.ChatRequestMessageClassification, content string) *azopenai.CompletionsUsage {
+	prompt := 0
+	for _, m := range messages {
+		_, text := messageText(m)
+		prompt += EstimateTokens(text)
+	}
+	completion := EstimateTokens(content)
+	return &azopenai.CompletionsUsage{
+		PromptTokens:     to.Ptr(int32(prompt)),
+		CompletionTokens: to.Ptr(int32(completion)),
+		TotalTokens:      to.Ptr(int32(prompt + completion)),
+	}
+}
+
+// echoResponse is the deterministic completion returned in echo mode.
+func echoResponse(messages []azopenai.ChatRequestMessageClassification) azopenai.GetChatCompletionsResponse {
+	content := echoContent(messages)
+	return azopenai.GetChatCompletionsResponse{ChatCompletions: azopenai.ChatCompletions{
+		Choices: []azopenai.ChatChoice{{
+			Message: &azopenai.ChatResponseMessage{
+				Role:    to.Ptr(azopenai.ChatRoleAssistant),
+				Content: to.Ptr(content),
+			},
+			FinishReason: to.Ptr(azopenai.CompletionsFinishReasonStopped),
+		}},
+		Usage: echoUsage(messages, content),
+	}}
+}
+
+// echoReader streams the echo answer one word per chunk.
+type echoReader struct {
+	chunks []azopenai.ChatCompletions
+}
+
+func newEchoReader(messages []azopenai.ChatRequestMessageClassification) *echoReader {
+	content := echoContent(messages)
+	r := &echoReader{}
+	for i, word := range strings.SplitAfter(content, " ") {
+		delta := &azopenai.ChatResponseMessage{Content: to.Ptr(word)}
+		if i == 0 {
+			delta.Role = to.Ptr(azopenai.ChatRoleAssistant)
+		}
+		r.chunks = append(r.chunks, azopenai.ChatCompletions{Choices: []azopenai.ChatChoice{{Delta: delta}}})
+	}
+	r.chunks = append(r.chunks, azopenai.ChatCompletions{
+		Choices: []azopenai.ChatChoice{{FinishReason: to.Ptr(azopenai.CompletionsFinishReasonStopped)}},
+		Usage:   echoUsage(messages, content),
+	})
+	return r
+}
+
+func (r *echoReader) Read() (azopenai.ChatCompletions, error) {
+	if len(r.chunks) == 0 {
+		return azopenai.ChatCompletions{}, io.EOF
+	}
+	chunk := r.chunks[0]
+	r.chunks = r.chunks[1:]
+	return chunk, nil
+}
@@ -0,0 +1,86 @@
+package azurrr
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EmbeddingCache stores embeddings for Client.Embed so identical texts are
+// only embedded once. Keys are opaque hashes of the text, deployment and
+// dimensions. Implementations must be safe for concurrent use and must not
+// let callers modify a cached vector.
+type EmbeddingCache interface {
+	Get(key string) ([]float32, bool)
+	Set(key string, embedding []float32)
+}
+
+// embeddingCacheKey hashes everything that determines an embedding.
+func embeddingCacheKey(deployment string, dimensions int, text string) string {
+	h := sha256.New()
+	h.Write([]byte(deployment))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(dimensions)))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// memoryEmbeddingCache is the in-process EmbeddingCache built from
+// EMBEDDING_CACHE_SIZE and EMBEDDING_CACHE_TTL. It evicts the least
+// recently used entry when full; a zero TTL never expires entries.
+type memoryEmbeddingCache struct {
+	mu      sync.Mutex
+	clock   Clock
+	ttl     time.Duration
+	size    int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key       string
+	embedding []float32
+	stored    time.Time
+}
+
+func newMemoryEmbeddingCache(clock Clock, size int, ttl time.Duration) *memoryEmbeddingCache {
+	return &memoryEmbeddingCache{clock: clock, ttl: ttl, size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (m *memoryEmbeddingCache) Get(key string) ([]float32, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if m.ttl > 0 && m.clock.Now().Sub(e.stored) > m.ttl {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return append([]float32(nil), e.embedding...), true
+}
+
+func (m *memoryEmbeddingCache) Set(key string, embedding []float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &cacheEntry{key: key, embedding: append([]float32(nil), embedding...), stored: m.clock.Now()}
+	if el, ok := m.entries[key]; ok {
+		el.Value = e
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(e)
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,191 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Embed returns one embedding per input from EmbeddingDeployment. With
+// EmbeddingRequestsPerMinute set, calls are paced by a token bucket that is
+// separate from the chat calls' limits. With an embedding cache, each input
+// is looked up first and only the misses are sent to Azure. A response
+// missing the vector for any input is an error, and nothing from it is
+// cached.
+func (c *Client) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if c.cfg.EmbeddingDeployment == "" {
+		return nil, errors.New("azurrr: Embed needs EMBEDDING_DEPLOYMENT_NAME")
+	}
+	out := make([][]float32, len(inputs))
+	var keys []string
+	var misses []int
+	for i, input := range inputs {
+		if c.embedCache == nil {
+			misses = append(misses, i)
+			continue
+		}
+		key := embeddingCacheKey(c.cfg.EmbeddingDeployment, c.cfg.EmbeddingDimensions, input)
+		keys = append(keys, key)
+		if v, ok := c.embedCache.Get(key); ok {
+			out[i] = v
+		} else {
+			misses = append(misses, i)
+		}
+	}
+	if len(misses) == 0 {
+		return out, nil
+	}
+	batch := make([]string, len(misses))
+	for j, i := range misses {
+		batch[j] = inputs[i]
+	}
+	opts := azopenai.EmbeddingsOptions{
+		Input:          batch,
+		DeploymentName: to.Ptr(c.cfg.EmbeddingDeployment),
+	}
+	if c.cfg.EmbeddingDimensions > 0 {
+		opts.Dimensions = to.Ptr(int32(c.cfg.EmbeddingDimensions))
+	}
+	var resp azopenai.GetEmbeddingsResponse
+	_, err := c.withRetry(ctx, func() error {
+		if err := c.embedLimiter.wait(ctx); err != nil {
+			return err
+		}
+		var raw *http.Response
+		var err error
+		resp, err = c.chat.GetEmbeddings(runtime.WithCaptureResponse(ctx, &raw), opts, nil)
+		if raw != nil {
+			c.embedLimiter.observe(raw.Header)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, c.wrapError(ctx, c.cfg.EmbeddingDeployment, err)
+	}
+	for j, item := range resp.Data {
+		if item.Index != nil {
+			j = int(*item.Index)
+		}
+		if j < 0 || j >= len(misses) {
+			continue
+		}
+		out[misses[j]] = item.Embedding
+	}
+	// Cache only once every input has its vector, so a short response
+	// leaves nothing behind.
+	for _, i := range misses {
+		if out[i] == nil {
+			return nil, fmt.Errorf("azurrr: embeddings response has no vector for input %d", i)
+		}
+	}
+	if c.embedCache != nil {
+		for _, i := range misses {
+			c.embedCache.Set(keys[i], out[i])
+		}
+	}
+	return out, nil
+}
+
+// tokenBucket paces requests to a per-minute rate, allowing bursts of up to
+// a minute's worth. A nil bucket never waits.
+type tokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	// until holds off every request until then, as the service's
+	// retry-after asked.
+	until time.Time
+}
+
+func newTokenBucket(clock Clock, perMinute int) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		clock:  clock,
+		rate:   float64(perMinute) / 60,
+		burst:  float64(perMinute),
+		tokens: float64(perMinute),
+		last:   clock.Now(),
+	}
+}
+
+// wait takes a token, sleeping until one is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		if hold := b.until.Sub(b.clock.Now()); hold > 0 {
+			b.mu.Unlock()
+			if err := b.clock.Sleep(ctx, hold); err != nil {
+				return err
+			}
+			continue
+		}
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		need := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		if err := b.clock.Sleep(ctx, need); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *tokenBucket) refill() {
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// observe applies the service's x-ratelimit headers: the bucket is emptied
+// when either no requests or no tokens remain, and a retry-after holds off
+// every request for that long, so the next call waits instead of being
+// throttled.
+func (b *tokenBucket) observe(h http.Header) {
+	if b == nil {
+		return
+	}
+	exhausted := headerInt(h, "x-ratelimit-remaining-requests") == 0 || headerInt(h, "x-ratelimit-remaining-tokens") == 0
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if d, ok := retryAfter(h, now); ok && now.Add(d).After(b.until) {
+		b.until = now.Add(d)
+	}
+	if exhausted {
+		b.refill()
+		b.tokens = 0
+	}
+}
+
+// retryAfter reads the retry-after header, in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package azurrr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock advances by each Sleep instead of sleeping.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.now = c.now.Add(d)
+	c.slept += d
+	return ctx.Err()
+}
+
+func TestTokenBucketObserve(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"quota left", http.Header{"X-Ratelimit-Remaining-Requests": {"5"}, "X-Ratelimit-Remaining-Tokens": {"900"}}, 0},
+		{"no requests left", http.Header{"X-Ratelimit-Remaining-Requests": {"0"}}, time.Second},
+		{"no tokens left", http.Header{"X-Ratelimit-Remaining-Tokens": {"0"}}, time.Second},
+		{"retry-after", http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{"retry-after date", http.Header{"Retry-After": {time.Unix(1003, 0).UTC().Format(http.TimeFormat)}}, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1000, 0)}
+			b := newTokenBucket(clock, 60)
+			b.observe(tt.header)
+			if err := b.wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if clock.slept != tt.want {
+				t.Errorf("waited %v, want %v", clock.slept, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedMissingVector(t *testing.T) {
+	cache := newMemoryEmbeddingCache(realClock{}, 10, 0)
+	c := newFakeClient(t, Config{EmbeddingDeployment: "ada"}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]},{"object":"embedding","index":5,"embedding":[0.3]}],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}, &ClientOptions{EmbeddingCache: cache})
+	if _, err := c.Embed(context.Background(), []string{"a", "b"}); err == nil || !strings.Contains(err.Error(), "no vector for input 1") {
+		t.Fatalf("Embed error = %v, want input 1 reported missing", err)
+	}
+	key := embeddingCacheKey("ada", 0, "a")
+	if _, ok := cache.Get(key); ok {
+		t.Error("partial response was cached")
+	}
+}
@@ -0,0 +1,93 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VectorizationError reports that On Your Data failed to embed the query,
+// which almost always means the embedding endpoint, its API version or its
+// key is wrong rather than anything about the chat deployment.
+type VectorizationError struct {
+	EmbeddingEndpoint string
+	Err               error
+}
+
+func (e *VectorizationError) Error() string {
+	return fmt.Sprintf("azurrr: query vectorization failed; check the embedding endpoint %q, its api-version and key: %v", e.EmbeddingEndpoint, e.Err)
+}
+
+func (e *VectorizationError) Unwrap() error { return e.Err }
+
+// ErrNoChoices is returned when the service answers with an empty choices
+// array, typically because the prompt was filtered. PromptFilterResults
+// carries the service's filter verdicts, if any. Set
+// Config.AllowEmptyChoices to get an empty result instead.
+type ErrNoChoices struct {
+	PromptFilterResults []azopenai.ContentFilterResultsForPrompt
+}
+
+func (e *ErrNoChoices) Error() string {
+	return fmt.Sprintf("azurrr: response contained no choices (%d prompt filter results)", len(e.PromptFilterResults))
+}
+
+// ErrMaxTokensTooLarge is returned before calling Azure when MaxTokens does
+// not fit in the deployment's context window next to the estimated prompt.
+// Budget is what is left for the completion. Set Config.ClampMaxTokens to
+// send Budget instead.
+type ErrMaxTokensTooLarge struct {
+	MaxTokens     int
+	PromptTokens  int
+	ContextTokens int
+	Budget        int
+}
+
+func (e *ErrMaxTokensTooLarge) Error() string {
+	return fmt.Sprintf("azurrr: MaxTokens %d exceeds the %d tokens left after an estimated %d-token prompt in a %d-token context",
+		e.MaxTokens, e.Budget, e.PromptTokens, e.ContextTokens)
+}
+
+// ErrDeploymentNotFound is returned when the service does not know the
+// deployment a call named. Suggestions holds similarly named deployments
+// when Config.SuggestDeployments is set and they could be listed.
+type ErrDeploymentNotFound struct {
+	Attempted   string
+	Suggestions []string
+	Err         error
+}
+
+func (e *ErrDeploymentNotFound) Error() string {
+	msg := fmt.Sprintf("azurrr: deployment %q not found", e.Attempted)
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = strconv.Quote(s)
+		}
+		msg += "; did you mean " + strings.Join(quoted, ", ") + "?"
+	}
+	return msg
+}
+
+func (e *ErrDeploymentNotFound) Unwrap() error { return e.Err }
+
+// wrapError turns known service failures into the package's typed errors.
+// deployment is the deployment the failed call named.
+func (c *Client) wrapError(ctx context.Context, deployment string, err error) error {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+	if respErr.StatusCode == http.StatusNotFound && respErr.ErrorCode == "DeploymentNotFound" {
+		return &ErrDeploymentNotFound{Attempted: deployment, Suggestions: c.suggestDeployments(ctx, deployment), Err: err}
+	}
+	if strings.Contains(strings.ToLower(respErr.Error()), "vectoriz") {
+		return &VectorizationError{EmbeddingEndpoint: c.cfg.EmbeddingEndpoint, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package azurrr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// HashRequest returns a stable hex SHA-256 over everything in opts that
+// affects the answer: messages, sampling parameters, deployment, tools and
+// extension configuration. User, which only identifies the caller, is
+// ignored. Fields are hashed in their JSON wire form with object keys
+// sorted, so map ordering such as LogitBias's does not change the hash. It
+// returns "" when opts cannot be marshaled, for example with a NaN
+// temperature, so such requests never share a key.
+func HashRequest(opts azopenai.ChatCompletionsOptions) string {
+	fields := wireFields(opts)
+	delete(fields, "user")
+	data, err := canonicalJSON(fields)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalJSON re-encodes v through a generic value so every object,
+// including those the SDK marshals itself, has its keys sorted.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// wireFields returns the fields of opts keyed by their JSON wire names, as
+// the SDK sends them. It is the single list of those names, shared by
+// HashRequest and the golden request tests.
+func wireFields(opts azopenai.ChatCompletionsOptions) map[string]any {
+	return map[string]any{
+		"data_sources":          opts.AzureExtensionsOptions,
+		"enhancements":          opts.Enhancements,
+		"frequency_penalty":     opts.FrequencyPenalty,
+		"function_call":         opts.FunctionCall,
+		"functions":             opts.Functions,
+		"logit_bias":            opts.LogitBias,
+		"logprobs":              opts.LogProbs,
+		"max_completion_tokens": opts.MaxCompletionTokens,
+		"max_tokens":            opts.MaxTokens,
+		"messages":              opts.Messages,
+		"model":                 opts.DeploymentName,
+		"n":                     opts.N,
+		"parallel_tool_calls":   opts.ParallelToolCalls,
+		"presence_penalty":      opts.PresencePenalty,
+		"response_format":       opts.ResponseFormat,
+		"seed":                  opts.Seed,
+		"stop":                  opts.Stop,
+		"temperature":           opts.Temperature,
+		"tool_choice":           opts.ToolChoice,
+		"tools":                 opts.Tools,
+		"top_logprobs":          opts.TopLogProbs,
+		"top_p":                 opts.TopP,
+		"user":                  opts.User,
+	}
+}
@@ -0,0 +1,61 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"math"
+	"testing"
+)
+
+func hashTestOptions() azopenai.ChatCompletionsOptions {
+	return azopenai.ChatCompletionsOptions{
+		Messages:       userMessages("What is the capital of France?"),
+		DeploymentName: to.Ptr("gpt-4o"),
+		MaxTokens:      to.Ptr[int32](100),
+		Temperature:    to.Ptr[float32](0.7),
+		LogitBias:      map[string]*int32{"1": to.Ptr[int32](1), "2": to.Ptr[int32](-1), "3": to.Ptr[int32](5)},
+	}
+}
+
+func TestHashRequestStable(t *testing.T) {
+	want := HashRequest(hashTestOptions())
+	if len(want) != 64 {
+		t.Fatalf("HashRequest = %q, want 64 hex digits", want)
+	}
+	for i := 0; i < 20; i++ {
+		if got := HashRequest(hashTestOptions()); got != want {
+			t.Fatalf("HashRequest changed between identical requests: %s != %s", got, want)
+		}
+	}
+	opts := hashTestOptions()
+	opts.User = to.Ptr("someone")
+	if got := HashRequest(opts); got != want {
+		t.Errorf("User changed the hash")
+	}
+}
+
+func TestHashRequestDistinguishes(t *testing.T) {
+	base := HashRequest(hashTestOptions())
+	changes := map[string]func(*azopenai.ChatCompletionsOptions){
+		"deployment":  func(o *azopenai.ChatCompletionsOptions) { o.DeploymentName = to.Ptr("gpt-4o-mini") },
+		"message":     func(o *azopenai.ChatCompletionsOptions) { o.Messages = userMessages("And of Spain?") },
+		"temperature": func(o *azopenai.ChatCompletionsOptions) { o.Temperature = to.Ptr[float32](0.2) },
+		"max tokens":  func(o *azopenai.ChatCompletionsOptions) { o.MaxTokens = to.Ptr[int32](50) },
+		"seed":        func(o *azopenai.ChatCompletionsOptions) { o.Seed = to.Ptr[int64](1) },
+	}
+	for name, change := range changes {
+		opts := hashTestOptions()
+		change(&opts)
+		if HashRequest(opts) == base {
+			t.Errorf("changing the %s did not change the hash", name)
+		}
+	}
+}
+
+func TestHashRequestUnmarshalable(t *testing.T) {
+	opts := hashTestOptions()
+	opts.Temperature = to.Ptr(float32(math.NaN()))
+	if got := HashRequest(opts); got != "" {
+		t.Errorf("HashRequest with a NaN temperature = %q, want empty", got)
+	}
+}
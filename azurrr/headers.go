@@ -0,0 +1,65 @@
+package azurrr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// headerPolicy overrides content negotiation headers for gateways that
+// insist on specific values.
+type headerPolicy struct {
+	contentType string
+	accept      string
+}
+
+func newHeaderPolicy(contentType, accept string) (*headerPolicy, error) {
+	if contentType != "" {
+		if err := checkMediaType(contentType, false); err != nil {
+			return nil, fmt.Errorf("azurrr: invalid ContentType %q: %w", contentType, err)
+		}
+	}
+	if accept != "" {
+		// Accept is a list of media ranges, each checked on its own.
+		for _, r := range strings.Split(accept, ",") {
+			if err := checkMediaType(strings.TrimSpace(r), true); err != nil {
+				return nil, fmt.Errorf("azurrr: invalid Accept %q: %w", accept, err)
+			}
+		}
+	}
+	return &headerPolicy{contentType: contentType, accept: accept}, nil
+}
+
+// checkMediaType requires v to be a type/subtype with optional parameters.
+// With ranges, Accept's */* and type/* wildcards are allowed too.
+// mime.ParseMediaType alone accepts a bare token such as "json".
+func checkMediaType(v string, ranges bool) error {
+	if strings.HasSuffix(strings.TrimSpace(v), ";") {
+		return errors.New("empty parameter")
+	}
+	mediaType, _, err := mime.ParseMediaType(v)
+	if err != nil {
+		return err
+	}
+	typ, sub, ok := strings.Cut(mediaType, "/")
+	if !ok || typ == "" || sub == "" {
+		return errors.New("want type/subtype")
+	}
+	if typ == "*" && sub != "*" || !ranges && (typ == "*" || sub == "*") {
+		return errors.New("invalid wildcard")
+	}
+	return nil
+}
+
+func (p *headerPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if p.contentType != "" {
+		req.Raw().Header.Set("Content-Type", p.contentType)
+	}
+	if p.accept != "" {
+		req.Raw().Header.Set("Accept", p.accept)
+	}
+	return req.Next()
+}
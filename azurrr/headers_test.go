@@ -0,0 +1,34 @@
+package azurrr
+
+import "testing"
+
+func TestNewHeaderPolicy(t *testing.T) {
+	tests := []struct {
+		contentType, accept string
+		wantErr             bool
+	}{
+		{"application/json", "", false},
+		{"", "application/json, text/event-stream", false},
+		{"", "text/event-stream;q=0.9,*/*;q=0.1", false},
+		{"application/json; charset=utf-8", "application/json", false},
+		{"not a type", "", true},
+		{"", "application/json, ", true},
+		{"", "application/json, bad type", true},
+		{"json", "", true},
+		{"application/json;", "", true},
+		{"application/", "", true},
+		{"*/*", "", true},
+		{"application/*", "", true},
+		{"", "*/*", false},
+		{"", "application/*", false},
+		{"", "json", true},
+		{"", "*/json", true},
+		{"", "application/json;", true},
+	}
+	for _, tt := range tests {
+		_, err := newHeaderPolicy(tt.contentType, tt.accept)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newHeaderPolicy(%q, %q) = %v, want error %v", tt.contentType, tt.accept, err, tt.wantErr)
+		}
+	}
+}
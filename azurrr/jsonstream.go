@@ -0,0 +1,102 @@
+package azurrr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidJSON is returned when streamed JSON-mode content does not form a
+// valid JSON document once the stream is complete.
+var ErrInvalidJSON = errors.New("azurrr: streamed content is not valid JSON")
+
+// JSONAssembler buffers the fragments of a JSON-mode stream, which are not
+// valid JSON on their own. Pass its Add method as StreamOptions.OnDelta and
+// call Unmarshal once the stream has finished. It is not safe for concurrent
+// use.
+type JSONAssembler struct {
+	buf  bytes.Buffer
+	pw   *io.PipeWriter
+	done chan error
+}
+
+// OnArrayElement additionally decodes a top-level JSON array while it is
+// still streaming, calling fn with each element as soon as it is complete.
+// It must be called before the first Add; Close reports its outcome.
+func (a *JSONAssembler) OnArrayElement(fn func(json.RawMessage) error) {
+	pr, pw := io.Pipe()
+	a.pw = pw
+	a.done = make(chan error, 1)
+	go func() {
+		err := DecodeArray(pr, fn)
+		// Unblock any further writes once decoding has stopped.
+		pr.CloseWithError(err)
+		a.done <- err
+	}()
+}
+
+// Add appends a streamed delta.
+func (a *JSONAssembler) Add(delta string) {
+	a.buf.WriteString(delta)
+	if a.pw != nil {
+		if _, err := a.pw.Write([]byte(delta)); err != nil {
+			a.pw = nil
+		}
+	}
+}
+
+// Close ends incremental decoding started by OnArrayElement and returns its
+// error. It is a no-op otherwise.
+func (a *JSONAssembler) Close() error {
+	if a.done == nil {
+		return nil
+	}
+	if a.pw != nil {
+		a.pw.Close()
+		a.pw = nil
+	}
+	err := <-a.done
+	a.done = nil
+	return err
+}
+
+// String returns the content buffered so far.
+func (a *JSONAssembler) String() string {
+	return a.buf.String()
+}
+
+// Unmarshal decodes the complete buffer into v.
+func (a *JSONAssembler) Unmarshal(v any) error {
+	if err := json.Unmarshal(a.buf.Bytes(), v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return nil
+}
+
+// DecodeArray reads a single top-level JSON array from r and calls fn with
+// each element in turn, without holding the whole array in memory.
+func DecodeArray(r io.Reader, fn func(json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("%w: expected array, got %v", ErrInvalidJSON, tok)
+	}
+	for dec.More() {
+		var elem json.RawMessage
+		if err := dec.Decode(&elem); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		if err := fn(elem); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return nil
+}
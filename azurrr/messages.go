@@ -0,0 +1,89 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// developerMessage is a developer role message. The SDK has no type for the
+// role, so it provides its own wire form.
+type developerMessage struct {
+	Content string
+}
+
+func (m *developerMessage) GetChatRequestMessage() *azopenai.ChatRequestMessage {
+	return &azopenai.ChatRequestMessage{}
+}
+
+func (m *developerMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Role    ChatRole `json:"role"`
+		Content string   `json:"content"`
+	}{RoleDeveloper, m.Content})
+}
+
+// developerFallback replaces developer messages with system messages for
+// deployments that do not support the developer role.
+func (c Config) developerFallback(messages []azopenai.ChatRequestMessageClassification) []azopenai.ChatRequestMessageClassification {
+	if c.DeveloperRole {
+		return messages
+	}
+	var out []azopenai.ChatRequestMessageClassification
+	for i, m := range messages {
+		dev, ok := m.(*developerMessage)
+		if !ok {
+			if out != nil {
+				out = append(out, m)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]azopenai.ChatRequestMessageClassification, 0, len(messages)), messages[:i]...)
+		}
+		out = append(out, &azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(dev.Content)})
+	}
+	if out == nil {
+		return messages
+	}
+	return out
+}
+
+// Turn is one message of a conversation in plain form. ToolCallID is only
+// used with the tool role, where it names the call being answered.
+type Turn struct {
+	Role       string
+	Content    string
+	ToolCallID string
+}
+
+// ToMessages converts turns into the SDK's request messages. Roles are the
+// system, developer, user, assistant and tool roles; any other role is an error, as is
+// a tool turn without a ToolCallID.
+func ToMessages(turns []Turn) ([]azopenai.ChatRequestMessageClassification, error) {
+	messages := make([]azopenai.ChatRequestMessageClassification, 0, len(turns))
+	for i, t := range turns {
+		switch ChatRole(t.Role) {
+		case RoleSystem:
+			messages = append(messages, &azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(t.Content)})
+		case RoleDeveloper:
+			messages = append(messages, &developerMessage{Content: t.Content})
+		case RoleUser:
+			messages = append(messages, &azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(t.Content)})
+		case RoleAssistant:
+			messages = append(messages, &azopenai.ChatRequestAssistantMessage{Content: azopenai.NewChatRequestAssistantMessageContent(t.Content)})
+		case RoleTool:
+			if t.ToolCallID == "" {
+				return nil, fmt.Errorf("azurrr: turn %d: tool turn has no ToolCallID", i)
+			}
+			messages = append(messages, &azopenai.ChatRequestToolMessage{
+				Content:    azopenai.NewChatRequestToolMessageContent(t.Content),
+				ToolCallID: to.Ptr(t.ToolCallID),
+			})
+		default:
+			return nil, fmt.Errorf("azurrr: turn %d: unknown role %q", i, t.Role)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,52 @@
+package azurrr
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type metadataKey struct{}
+
+// WithMetadata attaches md to ctx for the calls made with it, as
+// Params.Metadata does. It is the way to tag calls that take no Params,
+// such as Raw. Values in ctx are merged with, and overridden by, md.
+func WithMetadata(ctx context.Context, md map[string]string) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(metadataFrom(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(md))
+	}
+	maps.Copy(merged, md)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+func metadataFrom(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// MetadataError carries the metadata of the call that failed. Unwrap it, or
+// use errors.Is and errors.As, to reach the underlying error.
+type MetadataError struct {
+	Metadata map[string]string
+	Err      error
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("%v [%s]", e.Err, formatMetadata(e.Metadata))
+}
+
+func (e *MetadataError) Unwrap() error { return e.Err }
+
+func formatMetadata(md map[string]string) string {
+	parts := make([]string, 0, len(md))
+	for _, k := range slices.Sorted(maps.Keys(md)) {
+		parts = append(parts, k+"="+md[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,115 @@
+package azurrr
+
+import (
+	"encoding/json"
+)
+
+// openAIResponse is the OpenAI chat completion response schema, with the
+// Azure On Your Data context on the message.
+type openAIResponse struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   openAIUsage    `json:"usage"`
+}
+
+type openAIChoice struct {
+	Index        int           `json:"index"`
+	Message      openAIMessage `json:"message"`
+	FinishReason *string       `json:"finish_reason"`
+}
+
+type openAIMessage struct {
+	Role      ChatRole         `json:"role"`
+	Content   *string          `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+	Context   *openAIContext   `json:"context,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIContext struct {
+	Citations []openAICitation `json:"citations,omitempty"`
+	Intent    string           `json:"intent,omitempty"`
+}
+
+type openAICitation struct {
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url,omitempty"`
+	FilePath string `json:"filepath,omitempty"`
+	ChunkID  string `json:"chunk_id,omitempty"`
+	Content  string `json:"content"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ToOpenAIJSON encodes r as an OpenAI chat completion response, so it can
+// be served to clients that speak that format. Citations and search
+// queries go in the message's context object the way Azure On Your Data
+// sends them; plain OpenAI clients ignore it.
+func (r CompletionResult) ToOpenAIJSON() ([]byte, error) {
+	msg := openAIMessage{Role: r.Role}
+	if msg.Role == "" {
+		msg.Role = RoleAssistant
+	}
+	if r.Content != "" || len(r.ToolCalls) == 0 {
+		msg.Content = &r.Content
+	}
+	for _, tc := range r.ToolCalls {
+		call := openAIToolCall{ID: tc.ID, Type: "function"}
+		call.Function.Name = tc.Name
+		call.Function.Arguments = tc.Arguments
+		msg.ToolCalls = append(msg.ToolCalls, call)
+	}
+	if len(r.Citations) > 0 || len(r.SearchQueries) > 0 {
+		msg.Context = &openAIContext{}
+		for _, c := range r.Citations {
+			msg.Context.Citations = append(msg.Context.Citations, openAICitation{
+				Title:    c.Title,
+				URL:      c.URL,
+				FilePath: c.FilePath,
+				ChunkID:  c.ChunkID,
+				Content:  c.Content,
+			})
+		}
+		if len(r.SearchQueries) > 0 {
+			intent, err := json.Marshal(r.SearchQueries)
+			if err != nil {
+				return nil, err
+			}
+			msg.Context.Intent = string(intent)
+		}
+	}
+	choice := openAIChoice{Message: msg}
+	if r.FinishReason != "" {
+		choice.FinishReason = &r.FinishReason
+	}
+	resp := openAIResponse{
+		ID:      r.ID,
+		Object:  "chat.completion",
+		Model:   r.Model,
+		Choices: []openAIChoice{choice},
+		Usage: openAIUsage{
+			PromptTokens:     r.Usage.PromptTokens,
+			CompletionTokens: r.Usage.CompletionTokens,
+			TotalTokens:      r.Usage.TotalTokens,
+		},
+	}
+	if !r.Created.IsZero() {
+		resp.Created = r.Created.Unix()
+	}
+	return json.Marshal(resp)
+}
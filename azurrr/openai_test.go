@@ -0,0 +1,82 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"encoding/json"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"reflect"
+	"testing"
+)
+
+func TestToOpenAIJSONRoundTrip(t *testing.T) {
+	resp := test.NewResponse().
+		Content("Paris is the capital [doc1].").
+		Citation("France", "https://example.com/fr", "Paris is ...").
+		Intent("capital of France").
+		Usage(12, 7).
+		Build()
+	result := newCompletionResult(resp)
+
+	data, err := result.ToOpenAIJSON()
+	if err != nil {
+		t.Fatalf("ToOpenAIJSON: %v", err)
+	}
+
+	// Decode into the OpenAI schema, independently of the encoder's types.
+	var got struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Choices []struct {
+			Index   int `json:"index"`
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+				Context struct {
+					Citations []struct {
+						Title   string `json:"title"`
+						URL     string `json:"url"`
+						Content string `json:"content"`
+					} `json:"citations"`
+					Intent string `json:"intent"`
+				} `json:"context"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	if got.ID != "chatcmpl-test" || got.Object != "chat.completion" || len(got.Choices) != 1 {
+		t.Fatalf("response = %s", data)
+	}
+	choice := got.Choices[0]
+	if choice.Message.Role != "assistant" || choice.Message.Content != result.Content || choice.FinishReason != "stop" {
+		t.Errorf("choice = %+v", choice)
+	}
+	cites := choice.Message.Context.Citations
+	if len(cites) != 1 || cites[0].Title != "France" || cites[0].URL != "https://example.com/fr" || cites[0].Content != "Paris is ..." {
+		t.Errorf("context.citations = %+v", cites)
+	}
+	if choice.Message.Context.Intent != `["capital of France"]` {
+		t.Errorf("context.intent = %q", choice.Message.Context.Intent)
+	}
+	if got.Usage.PromptTokens != 12 || got.Usage.CompletionTokens != 7 || got.Usage.TotalTokens != 19 {
+		t.Errorf("usage = %+v", got.Usage)
+	}
+
+	// The SDK parses the output back into the same result.
+	var back azopenai.ChatCompletions
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("SDK decoding: %v", err)
+	}
+	again := newCompletionResult(azopenai.GetChatCompletionsResponse{ChatCompletions: back})
+	if again.Content != result.Content || !reflect.DeepEqual(again.Citations, result.Citations) ||
+		!reflect.DeepEqual(again.SearchQueries, result.SearchQueries) || again.Usage != result.Usage {
+		t.Errorf("round trip = %+v, want %+v", again, result)
+	}
+}
@@ -0,0 +1,62 @@
+package azurrr
+
+import (
+	"context"
+	"time"
+)
+
+// PromptResult is the outcome of one prompt in a pipeline run.
+type PromptResult struct {
+	Prompt  string
+	Result  CompletionResult
+	Err     error
+	Latency time.Duration
+}
+
+// PipelineSummary aggregates a pipeline run.
+type PipelineSummary struct {
+	Results []PromptResult
+	Failed  int
+
+	TotalLatency time.Duration
+	MeanLatency  time.Duration
+	MaxLatency   time.Duration
+
+	Usage Usage
+}
+
+// RunPrompts asks each prompt in order, one at a time, so evaluation runs are
+// reproducible. progress, if non-nil, is called after every prompt. A failed
+// prompt is recorded and the run continues; only cancellation of ctx stops it
+// early, in which case the summary covers the prompts completed so far.
+func (c *Client) RunPrompts(ctx context.Context, prompts []string, p Params, progress func(done, total int)) (PipelineSummary, error) {
+	summary := PipelineSummary{Results: make([]PromptResult, 0, len(prompts))}
+	var err error
+	for i, prompt := range prompts {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		start := c.clock.Now()
+		result, askErr := c.Ask(ctx, prompt, p)
+		latency := c.clock.Now().Sub(start)
+
+		summary.Results = append(summary.Results, PromptResult{Prompt: prompt, Result: result, Err: askErr, Latency: latency})
+		if askErr != nil {
+			summary.Failed++
+		}
+		summary.TotalLatency += latency
+		if latency > summary.MaxLatency {
+			summary.MaxLatency = latency
+		}
+		summary.Usage.PromptTokens += result.Usage.PromptTokens
+		summary.Usage.CompletionTokens += result.Usage.CompletionTokens
+		summary.Usage.TotalTokens += result.Usage.TotalTokens
+		if progress != nil {
+			progress(i+1, len(prompts))
+		}
+	}
+	if n := len(summary.Results); n > 0 {
+		summary.MeanLatency = summary.TotalLatency / time.Duration(n)
+	}
+	return summary, err
+}
@@ -0,0 +1,54 @@
+package azurrr
+
+import (
+	"context"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"net/http"
+	"strconv"
+)
+
+// RemainingQuota is a best-effort look at the deployment's rate limits. It
+// sends a one-token completion and reads the x-ratelimit-remaining-requests
+// and x-ratelimit-remaining-tokens headers, returning -1 for any header the
+// service did not send. The probe itself consumes a little quota, counts
+// against Config.MaxConcurrent and is retried like other calls. In echo mode
+// there is no service to ask and both values are -1.
+func (c *Client) RemainingQuota(ctx context.Context) (requests, tokens int, err error) {
+	if c.cfg.Echo {
+		return -1, -1, nil
+	}
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return -1, -1, err
+	}
+	defer release()
+	var raw *http.Response
+	probe := azopenai.ChatCompletionsOptions{
+		Messages: []azopenai.ChatRequestMessageClassification{
+			&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent("ping")},
+		},
+		MaxTokens:      to.Ptr[int32](1),
+		DeploymentName: to.Ptr(c.cfg.Deployment),
+	}
+	_, err = c.withRetry(ctx, func() error {
+		_, err := c.chat.GetChatCompletions(runtime.WithCaptureResponse(ctx, &raw), probe, nil)
+		return err
+	})
+	if err != nil {
+		return -1, -1, c.wrapError(ctx, c.cfg.Deployment, err)
+	}
+	if raw == nil {
+		return -1, -1, nil
+	}
+	return headerInt(raw.Header, "x-ratelimit-remaining-requests"), headerInt(raw.Header, "x-ratelimit-remaining-tokens"), nil
+}
+
+func headerInt(h http.Header, name string) int {
+	n, err := strconv.Atoi(h.Get(name))
+	if err != nil {
+		return -1
+	}
+	return n
+}
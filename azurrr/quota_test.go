@@ -0,0 +1,55 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRemainingQuota(t *testing.T) {
+	var calls atomic.Int32
+	ok := respondWith(test.NewResponse().Content("pong").Build())
+	c := newFakeClient(t, Config{MaxRetries: 1}, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("x-ratelimit-remaining-requests", "42")
+		w.Header().Set("x-ratelimit-remaining-tokens", "9000")
+		ok(w, r)
+	}, nil)
+	requests, tokens, err := c.RemainingQuota(context.Background())
+	if err != nil {
+		t.Fatalf("RemainingQuota: %v", err)
+	}
+	if requests != 42 || tokens != 9000 {
+		t.Errorf("RemainingQuota = %d, %d, want 42, 9000", requests, tokens)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2 with the 429 retried", n)
+	}
+}
+
+func TestRemainingQuotaEcho(t *testing.T) {
+	requests, tokens, err := echoClient(t).RemainingQuota(context.Background())
+	if err != nil || requests != -1 || tokens != -1 {
+		t.Errorf("RemainingQuota = %d, %d, %v, want -1, -1, nil", requests, tokens, err)
+	}
+}
+
+func TestRemainingQuotaHonorsLimiter(t *testing.T) {
+	c := newFakeClient(t, Config{MaxConcurrent: 1, OverflowPolicy: OverflowReject}, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("probe sent while the limit was reached")
+	}, nil)
+	release, err := c.limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if _, _, err := c.RemainingQuota(context.Background()); !errors.Is(err, ErrConcurrencyLimit) {
+		t.Errorf("RemainingQuota error = %v, want ErrConcurrencyLimit", err)
+	}
+}
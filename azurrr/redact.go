@@ -0,0 +1,50 @@
+package azurrr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted returns a copy of c with every field tagged secret masked, so it
+// can be logged.
+func (c Config) Redacted() Config {
+	v := reflect.ValueOf(&c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("secret") == "true" {
+			f := v.Field(i)
+			f.SetString(maskSecret(f.String()))
+		}
+	}
+	return c
+}
+
+// String lists the redacted configuration one setting per line, in field
+// order, as NAME=value using the environment variable names.
+func (c Config) String() string {
+	v := reflect.ValueOf(c.Redacted())
+	t := v.Type()
+	var b strings.Builder
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("envconfig")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		fmt.Fprintf(&b, "%s=%v\n", name, v.Field(i).Interface())
+	}
+	return b.String()
+}
+
+// maskSecret keeps just enough of a secret to tell keys apart: the first
+// three and last four characters of long values, nothing of short ones.
+func maskSecret(s string) string {
+	switch {
+	case s == "":
+		return ""
+	case len(s) < 12:
+		return "***"
+	default:
+		return s[:3] + "..." + s[len(s)-4:]
+	}
+}
@@ -0,0 +1,76 @@
+package azurrr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withSecrets returns a config with every secret field set to a distinct
+// value, keyed by environment variable name.
+func withSecrets(t *testing.T) (Config, map[string]string) {
+	t.Helper()
+	cfg := Config{Deployment: "gpt-4o"}
+	secrets := map[string]string{}
+	v := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Tag.Get("secret") != "true" {
+			continue
+		}
+		if f.Type.Kind() != reflect.String {
+			t.Fatalf("secret field %s is a %s; Redacted only masks strings", f.Name, f.Type)
+		}
+		secret := "sk-" + f.Name + "-0123456789"
+		v.Field(i).SetString(secret)
+		secrets[f.Tag.Get("envconfig")] = secret
+	}
+	if len(secrets) == 0 {
+		t.Fatal("no fields are tagged secret")
+	}
+	return cfg, secrets
+}
+
+func TestConfigStringMasksSecrets(t *testing.T) {
+	cfg, secrets := withSecrets(t)
+	out := cfg.String()
+	lines := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("line %q is not NAME=value", line)
+		}
+		lines[name] = value
+	}
+	if n := reflect.TypeOf(cfg).NumField(); len(lines) != n {
+		t.Errorf("String has %d settings, want one per field (%d)", len(lines), n)
+	}
+	if lines["DEPLOYMENT_NAME"] != "gpt-4o" {
+		t.Errorf("DEPLOYMENT_NAME=%q, want gpt-4o", lines["DEPLOYMENT_NAME"])
+	}
+	for name, secret := range secrets {
+		if strings.Contains(out, secret) {
+			t.Errorf("String contains the value of %s", name)
+		}
+		if want := maskSecret(secret); lines[name] != want {
+			t.Errorf("%s=%q, want %q", name, lines[name], want)
+		}
+	}
+	if again := cfg.String(); again != out {
+		t.Error("String is not stable between calls")
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := map[string]string{
+		"":                    "",
+		"short":               "***",
+		"elevenchars":         "***",
+		"abc-0123456789-wxyz": "abc...wxyz",
+	}
+	for in, want := range tests {
+		if got := maskSecret(in); got != want {
+			t.Errorf("maskSecret(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
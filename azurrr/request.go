@@ -0,0 +1,202 @@
+package azurrr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"log"
+	"net/url"
+	"strings"
+)
+
+var endpointType azopenai.OnYourDataVectorizationSourceType = "endpoint"
+
+// completionOptions builds a plain request for messages with no extensions.
+func (c *Client) completionOptions(messages []azopenai.ChatRequestMessageClassification, p Params) azopenai.ChatCompletionsOptions {
+	opts := azopenai.ChatCompletionsOptions{
+		Messages:       c.cfg.developerFallback(messages),
+		MaxTokens:      c.cfg.maxTokens(c.cfg.Deployment, p),
+		DeploymentName: to.Ptr(c.cfg.Deployment),
+	}
+	// NewClient has already rejected enhancements the config cannot use.
+	opts.Enhancements, _ = c.cfg.enhancements()
+	if p.Temperature != nil && p.TopP != nil {
+		log.Printf("azurrr: both Temperature and TopP are set; only the %s mode's value is sent", p.sampling())
+	}
+	p = applyDefaults(p)
+	opts.FrequencyPenalty = p.FrequencyPenalty
+	opts.PresencePenalty = p.PresencePenalty
+	if p.sampling() == SampleTopP {
+		opts.TopP = p.TopP
+	} else {
+		opts.Temperature = p.Temperature
+	}
+	if len(p.Tools) > 0 {
+		// The service rejects parallel_tool_calls without tools.
+		opts.Tools = p.Tools
+		opts.ParallelToolCalls = p.ParallelToolCalls
+	}
+	return opts
+}
+
+func (p Params) sampling() SamplingMode {
+	if p.Sampling == "" {
+		return SampleTemperature
+	}
+	return p.Sampling
+}
+
+// groundedOptions builds the request for messages, grounded on the configured
+// search index when there is one.
+func (c *Client) groundedOptions(messages []azopenai.ChatRequestMessageClassification, p Params) (azopenai.ChatCompletionsOptions, error) {
+	opts := c.completionOptions(messages, p)
+	ext, err := c.cfg.searchExtension(p)
+	if err != nil {
+		return azopenai.ChatCompletionsOptions{}, err
+	}
+	if ext != nil {
+		opts.AzureExtensionsOptions = []azopenai.AzureChatExtensionConfigurationClassification{ext}
+	}
+	return opts, nil
+}
+
+// searchExtension returns the Azure Search On Your Data extension, or nil when
+// no search endpoint is configured.
+func (c Config) searchExtension(p Params) (*azopenai.AzureSearchChatExtensionConfiguration, error) {
+	if c.SearchEndpoint == "" {
+		return nil, nil
+	}
+	params := &azopenai.AzureSearchChatExtensionParameters{
+		Endpoint:              to.Ptr(c.SearchEndpoint),
+		IndexName:             to.Ptr(c.SearchIndex),
+		Authentication:        c.searchAuth(),
+		Strictness:            to.Ptr(c.Strictness),
+		InScope:               to.Ptr(c.InScope),
+		TopNDocuments:         to.Ptr(c.TopNDocuments),
+		QueryType:             to.Ptr(azopenai.AzureSearchQueryType(c.SearchQueryType)),
+		SemanticConfiguration: to.Ptr(c.SemanticConfiguration),
+	}
+	if p.InScope != nil {
+		params.InScope = to.Ptr(*p.InScope)
+	}
+	if c.SearchAllowPartialResult {
+		params.AllowPartialResult = to.Ptr(true)
+		// The retrieved documents are needed to tell whether any query
+		// came back empty.
+		params.IncludeContexts = []azopenai.OnYourDataContextProperty{
+			azopenai.OnYourDataContextPropertyCitations,
+			azopenai.OnYourDataContextPropertyIntent,
+			azopenai.OnYourDataContextPropertyAllRetrievedDocuments,
+		}
+	}
+	filter, err := c.searchFilter(p)
+	if err != nil {
+		return nil, err
+	}
+	if filter != "" {
+		params.Filter = &filter
+	}
+	if c.EmbeddingEndpoint != "" {
+		embeddingEndpoint, err := c.embeddingEndpoint()
+		if err != nil {
+			return nil, err
+		}
+		params.EmbeddingDependency = &azopenai.OnYourDataEndpointVectorizationSource{
+			Authentication: c.embeddingAuth(),
+			Endpoint:       to.Ptr(embeddingEndpoint),
+			Type:           &endpointType,
+		}
+	}
+	return &azopenai.AzureSearchChatExtensionConfiguration{Parameters: params}, nil
+}
+
+// embeddingEndpoint applies EmbeddingAPIVersion to EmbeddingEndpoint.
+func (c Config) embeddingEndpoint() (string, error) {
+	if c.EmbeddingAPIVersion == "" {
+		return c.EmbeddingEndpoint, nil
+	}
+	u, err := url.Parse(c.EmbeddingEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("azurrr: invalid embedding endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("api-version", c.EmbeddingAPIVersion)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// searchFilter combines the configured or per-call filter with FileFilter.
+func (c Config) searchFilter(p Params) (string, error) {
+	filter, source := c.SearchFilter, "SEARCH_FILTER"
+	if p.Filter != nil {
+		filter, source = *p.Filter, "Params.Filter"
+	}
+	if filter != "" || p.Filter != nil {
+		if strings.TrimSpace(filter) == "" {
+			return "", fmt.Errorf("azurrr: %s is set but empty", source)
+		}
+	}
+	if len(p.FileFilter) == 0 {
+		return filter, nil
+	}
+	files, err := fileFilter(c.FilePathField, p.FileFilter)
+	if err != nil {
+		return "", err
+	}
+	if filter == "" {
+		return files, nil
+	}
+	return "(" + filter + ") and (" + files + ")", nil
+}
+
+// fileFilter builds an OData filter matching any of ids on field.
+func fileFilter(field string, ids []string) (string, error) {
+	clauses := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			return "", errors.New("azurrr: FileFilter contains an empty file ID")
+		}
+		clauses = append(clauses, fmt.Sprintf("%s eq '%s'", field, strings.ReplaceAll(id, "'", "''")))
+	}
+	return strings.Join(clauses, " or "), nil
+}
+
+// scopeEnforced reports whether opts restrict answers to the search index.
+func scopeEnforced(opts []azopenai.AzureChatExtensionConfigurationClassification) bool {
+	for _, ext := range opts {
+		if search, ok := ext.(*azopenai.AzureSearchChatExtensionConfiguration); ok && search.Parameters != nil {
+			return deref(search.Parameters.InScope)
+		}
+	}
+	return false
+}
+
+// streamOptions copies a request into the streaming variant the SDK expects.
+func streamOptions(o azopenai.ChatCompletionsOptions) azopenai.ChatCompletionsStreamOptions {
+	return azopenai.ChatCompletionsStreamOptions{
+		Messages:               o.Messages,
+		AzureExtensionsOptions: o.AzureExtensionsOptions,
+		Enhancements:           o.Enhancements,
+		FrequencyPenalty:       o.FrequencyPenalty,
+		FunctionCall:           o.FunctionCall,
+		Functions:              o.Functions,
+		LogitBias:              o.LogitBias,
+		LogProbs:               o.LogProbs,
+		MaxCompletionTokens:    o.MaxCompletionTokens,
+		MaxTokens:              o.MaxTokens,
+		DeploymentName:         o.DeploymentName,
+		N:                      o.N,
+		ParallelToolCalls:      o.ParallelToolCalls,
+		PresencePenalty:        o.PresencePenalty,
+		ResponseFormat:         o.ResponseFormat,
+		Seed:                   o.Seed,
+		Stop:                   o.Stop,
+		Temperature:            o.Temperature,
+		ToolChoice:             o.ToolChoice,
+		Tools:                  o.Tools,
+		TopLogProbs:            o.TopLogProbs,
+		TopP:                   o.TopP,
+		User:                   o.User,
+	}
+}
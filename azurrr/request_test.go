@@ -0,0 +1,160 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"encoding/json"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func searchConfig() Config {
+	return Config{
+		Deployment:      "gpt-4o",
+		MaxTokens:       100,
+		SearchEndpoint:  "https://search.example.com",
+		SearchIndex:     "docs",
+		SearchAPIKey:    "search-key",
+		SearchQueryType: "simple",
+		FilePathField:   "filepath",
+	}
+}
+
+// searchFilterOf returns the filter of the Azure Search data source in opts.
+func searchFilterOf(opts azopenai.ChatCompletionsOptions) string {
+	for _, ext := range opts.AzureExtensionsOptions {
+		if search, ok := ext.(*azopenai.AzureSearchChatExtensionConfiguration); ok && search.Parameters != nil {
+			return deref(search.Parameters.Filter)
+		}
+	}
+	return ""
+}
+
+func TestSearchFilterReachesRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		p      Params
+		want   string
+	}{
+		{name: "none"},
+		{name: "config", config: "tenant eq 'a'", want: "tenant eq 'a'"},
+		{name: "per call overrides config", config: "tenant eq 'a'", p: Params{Filter: to.Ptr("tenant eq 'b'")}, want: "tenant eq 'b'"},
+		{name: "file filter", p: Params{FileFilter: []string{"x.md"}}, want: "filepath eq 'x.md'"},
+		{
+			name:   "config and file filter",
+			config: "tenant eq 'a'",
+			p:      Params{FileFilter: []string{"x.md", "o'b.md"}},
+			want:   "(tenant eq 'a') and (filepath eq 'x.md' or filepath eq 'o''b.md')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := searchConfig()
+			cfg.SearchFilter = tt.config
+			c := &Client{cfg: cfg}
+			opts, err := c.groundedOptions(userMessages("q"), tt.p)
+			if err != nil {
+				t.Fatalf("groundedOptions: %v", err)
+			}
+			if got := searchFilterOf(opts); got != tt.want {
+				t.Errorf("Filter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchFilterEmptyIsRejected(t *testing.T) {
+	c := &Client{cfg: searchConfig()}
+	if _, err := c.groundedOptions(userMessages("q"), Params{Filter: to.Ptr("  ")}); err == nil {
+		t.Fatal("groundedOptions accepted a blank Params.Filter")
+	}
+}
+
+func TestSearchFilterOnTheWire(t *testing.T) {
+	var body struct {
+		DataSources []struct {
+			Parameters struct {
+				Filter string `json:"filter"`
+			} `json:"parameters"`
+		} `json:"data_sources"`
+	}
+	reply := respondWith(test.NewResponse().Content("ok").Build())
+	cfg := searchConfig()
+	cfg.SearchFilter = "tenant eq 'a'"
+	c := newFakeClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		reply(w, r)
+	}, nil)
+	if _, err := c.Ask(context.Background(), "q", Params{}); err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if len(body.DataSources) != 1 || body.DataSources[0].Parameters.Filter != "tenant eq 'a'" {
+		t.Errorf("data_sources = %+v, want one source filtered by tenant eq 'a'", body.DataSources)
+	}
+}
+
+func TestGroundedOptionsGolden(t *testing.T) {
+	cfg := searchConfig()
+	cfg.SearchFilter = "tenant eq 'a'"
+	cfg.Strictness = 3
+	cfg.TopNDocuments = 5
+	cfg.InScope = true
+	c := &Client{cfg: cfg}
+	opts, err := c.groundedOptions(userMessages("What is the refund policy?"), Params{Temperature: to.Ptr[float32](0.2)})
+	if err != nil {
+		t.Fatalf("groundedOptions: %v", err)
+	}
+	test.AssertRequest(t, "testdata/grounded_options.golden.json", wireFields(opts))
+}
+
+// TestRequestCopiesCoverEveryField fails when an SDK upgrade adds a request
+// field that wireFields or streamOptions does not carry.
+func TestRequestCopiesCoverEveryField(t *testing.T) {
+	var opts azopenai.ChatCompletionsOptions
+	v := reflect.ValueOf(&opts).Elem()
+	set := 0
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Pointer:
+			f.Set(reflect.New(f.Type().Elem()))
+		case reflect.Slice:
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		case reflect.Map:
+			f.Set(reflect.MakeMap(f.Type()))
+		default:
+			continue
+		}
+		set++
+	}
+	if n := len(wireFields(opts)); n != v.NumField() {
+		t.Errorf("wireFields has %d fields, ChatCompletionsOptions has %d", n, v.NumField())
+	}
+	nonNil := 0
+	for _, f := range wireFields(opts) {
+		if rv := reflect.ValueOf(f); rv.IsValid() && !rv.IsNil() {
+			nonNil++
+		}
+	}
+	if nonNil != set {
+		t.Errorf("wireFields carries %d of %d set fields", nonNil, set)
+	}
+	stream := reflect.ValueOf(streamOptions(opts))
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		got := stream.FieldByName(name)
+		if !got.IsValid() {
+			t.Errorf("ChatCompletionsStreamOptions has no field %s", name)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), v.Field(i).Interface()) {
+			t.Errorf("streamOptions does not copy %s", name)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"strings"
+	"time"
+)
+
+// CompletionResult is the parsed form of a chat completions response.
+type CompletionResult struct {
+	// ID, Model and Created identify the response as the service reported
+	// it; they are empty for echo completions.
+	ID      string
+	Model   string
+	Created time.Time
+	// StoredID is the key for Client.StoredCompletion when the service kept
+	// the completion under Config.StoreCompletions; empty otherwise.
+	StoredID string
+
+	Role         ChatRole
+	Content      string
+	FinishReason string
+
+	// Grounded and Refused are a heuristic classification of the answer; see
+	// classifyGrounding for its limitations.
+	Grounded bool
+	Refused  bool
+
+	// Fallback is set when the answer is Config.FallbackMessage.
+	Fallback bool
+
+	// LowConfidence is set when a grounded answer cites fewer than
+	// Config.MinCitations sources.
+	LowConfidence bool
+
+	// ScopeEnforced is set when the request limited answers to the search
+	// index, from Config.InScope or the Params.InScope override.
+	ScopeEnforced bool
+
+	// Partial is set when a stream failed before completing; Content holds
+	// what was received up to the failure.
+	Partial bool
+	// Reconnects is how many times a failed stream was resumed; see
+	// StreamOptions.Reconnects.
+	Reconnects int
+
+	// Enhanced is set when the service applied the requested Azure
+	// enhancements; deployments without them silently omit the output.
+	Enhanced bool
+
+	// Shared is set when the result came from another caller's identical
+	// in-flight request under Config.CoalesceRequests.
+	Shared bool
+
+	// Continuations is how many times an answer cut off at MaxTokens was
+	// continued; see Config.MaxContinuations.
+	Continuations int
+
+	// Retry reports the retries spent before the call succeeded.
+	Retry RetryStats
+
+	// Stream holds latency metrics; it is only set by streaming calls.
+	Stream *StreamMetrics
+
+	// Usage is the token accounting reported by the service. Streaming calls
+	// only have it if the service sends it in the final chunk.
+	Usage Usage
+
+	// ToolCalls are the function calls the model requested.
+	ToolCalls []ToolCall
+	// ToolRounds is how many rounds of tool calls RunTools executed.
+	ToolRounds int
+
+	// Citations are the sources the answer cites, in the order the service
+	// returned them.
+	Citations []Citation
+	// SourcesUsed names the data sources the citations came from, in order
+	// of first use.
+	SourcesUsed []string
+
+	// SearchQueries are the queries Azure Search actually ran after rewriting
+	// the user's question. Nil when the service reported none, for example
+	// with query rewriting disabled.
+	SearchQueries []string
+
+	// PartialSearch is set when at least one executed search query
+	// contributed no documents, as happens when SearchAllowPartialResult lets
+	// an answer through despite failed queries. It is only detected when the
+	// retrieved documents are in the context, which that setting requests.
+	PartialSearch bool
+
+	// RetrievedDocuments are the documents On Your Data used to ground the
+	// answer. Empty when the response carried no extension context.
+	RetrievedDocuments []RetrievedDocument
+}
+
+// ToolCall is a function invocation requested by the model.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// Usage is the token count for one call.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Citation is a source referenced by a grounded answer.
+type Citation struct {
+	Title    string
+	URL      string
+	FilePath string
+	ChunkID  string
+	Content  string
+	// Source names the data source the citation came from; see
+	// CompletionResult.SourcesUsed.
+	Source string
+}
+
+// RetrievedDocument is a search document returned in the extension context.
+type RetrievedDocument struct {
+	Title    string
+	URL      string
+	FilePath string
+	ChunkID  string
+	Content  string
+}
+
+func newCompletionResult(resp azopenai.GetChatCompletionsResponse) CompletionResult {
+	result := CompletionResult{
+		ID:      deref(resp.ID),
+		Model:   deref(resp.Model),
+		Created: deref(resp.Created),
+	}
+	if u := resp.Usage; u != nil {
+		result.Usage = Usage{
+			PromptTokens:     int(deref(u.PromptTokens)),
+			CompletionTokens: int(deref(u.CompletionTokens)),
+			TotalTokens:      int(deref(u.TotalTokens)),
+		}
+	}
+	if len(resp.Choices) == 0 {
+		return result
+	}
+	msg := resp.Choices[0].Message
+	if msg == nil {
+		return result
+	}
+	if msg.Role != nil {
+		result.Role = ChatRole(*msg.Role)
+	}
+	result.Content = deref(msg.Content)
+	result.Enhanced = resp.Choices[0].Enhancements != nil
+	if fr := resp.Choices[0].FinishReason; fr != nil {
+		result.FinishReason = string(*fr)
+	}
+	result.ToolCalls = toolCalls(msg.ToolCalls)
+	result.Citations = citations(msg.Context)
+	result.RetrievedDocuments = retrievedDocuments(msg.Context)
+	result.SearchQueries = searchQueries(msg.Context)
+	result.PartialSearch = partialSearch(msg.Context)
+	result.classifyGrounding(deref(msg.Refusal))
+	return result
+}
+
+func toolCalls(calls []azopenai.ChatCompletionsToolCallClassification) []ToolCall {
+	var out []ToolCall
+	for _, tc := range calls {
+		fn, ok := tc.(*azopenai.ChatCompletionsFunctionToolCall)
+		if !ok || fn.Function == nil {
+			continue
+		}
+		out = append(out, ToolCall{
+			ID:        deref(fn.ID),
+			Name:      deref(fn.Function.Name),
+			Arguments: deref(fn.Function.Arguments),
+		})
+	}
+	return out
+}
+
+// annotate records what the request asked for on its result.
+func (c *Client) annotate(r *CompletionResult, opts azopenai.ChatCompletionsOptions) {
+	r.ScopeEnforced = scopeEnforced(opts.AzureExtensionsOptions)
+	if c.cfg.StoreCompletions && !c.cfg.Echo {
+		r.StoredID = r.ID
+	}
+	r.tagSources(opts.AzureExtensionsOptions)
+	if fallback := strings.TrimSpace(c.cfg.FallbackMessage); fallback != "" && len(r.Citations) == 0 &&
+		strings.EqualFold(strings.TrimSpace(r.Content), fallback) {
+		r.Fallback = true
+		r.Refused = true
+	}
+}
+
+// tagSources attributes the citations to the request's data sources. The
+// response context does not say which source a citation came from, so they
+// can only be attributed when the request had exactly one, which is all On
+// Your Data accepts today; otherwise Source stays empty.
+func (r *CompletionResult) tagSources(exts []azopenai.AzureChatExtensionConfigurationClassification) {
+	if len(exts) != 1 || len(r.Citations) == 0 {
+		return
+	}
+	name := sourceName(exts[0])
+	for i := range r.Citations {
+		r.Citations[i].Source = name
+	}
+	r.SourcesUsed = []string{name}
+}
+
+// sourceName is the index name of a search source, or the SDK type name of
+// any other source.
+func sourceName(ext azopenai.AzureChatExtensionConfigurationClassification) string {
+	if search, ok := ext.(*azopenai.AzureSearchChatExtensionConfiguration); ok && search.Parameters != nil {
+		if name := deref(search.Parameters.IndexName); name != "" {
+			return name
+		}
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", ext), "*azopenai.")
+}
+
+// refusalPhrases are the canned answers On Your Data gives when InScope is
+// set and nothing relevant was retrieved.
+var refusalPhrases = []string{
+	"the requested information is not available in the retrieved data",
+	"the requested information is not found in the retrieved data",
+}
+
+// classifyGrounding sets Grounded when the answer cites at least one source
+// and Refused when it carries no citations and either the model reported a
+// refusal, the content was filtered, or the text matches a known canned
+// out-of-scope reply. It is a heuristic: a custom system prompt can change
+// the wording of refusals, an answer may cite nothing yet still be drawn from
+// the index, and ungrounded calls such as Complete are never Grounded.
+func (r *CompletionResult) classifyGrounding(refusal string) {
+	r.Grounded = len(r.Citations) > 0
+	if r.Grounded {
+		return
+	}
+	if refusal != "" || r.FinishReason == string(azopenai.CompletionsFinishReasonContentFiltered) {
+		r.Refused = true
+		return
+	}
+	lower := strings.ToLower(r.Content)
+	for _, phrase := range refusalPhrases {
+		if strings.Contains(lower, phrase) {
+			r.Refused = true
+			return
+		}
+	}
+}
+
+func citations(ctx *azopenai.AzureChatExtensionsMessageContext) []Citation {
+	if ctx == nil {
+		return nil
+	}
+	var out []Citation
+	for _, c := range ctx.Citations {
+		out = append(out, Citation{
+			Title:    deref(c.Title),
+			URL:      deref(c.URL),
+			FilePath: deref(c.FilePath),
+			ChunkID:  deref(c.ChunkID),
+			Content:  deref(c.Content),
+		})
+	}
+	return out
+}
+
+// retrievedDocuments prefers the full retrieved document list and falls back
+// to the citations when the service only returned those.
+func retrievedDocuments(ctx *azopenai.AzureChatExtensionsMessageContext) []RetrievedDocument {
+	if ctx == nil {
+		return nil
+	}
+	var docs []RetrievedDocument
+	for _, d := range ctx.AllRetrievedDocuments {
+		docs = append(docs, RetrievedDocument{
+			Title:    deref(d.Title),
+			URL:      deref(d.URL),
+			FilePath: deref(d.FilePath),
+			ChunkID:  deref(d.ChunkID),
+			Content:  deref(d.Content),
+		})
+	}
+	if len(docs) > 0 {
+		return docs
+	}
+	for _, c := range ctx.Citations {
+		docs = append(docs, RetrievedDocument{
+			Title:    deref(c.Title),
+			URL:      deref(c.URL),
+			FilePath: deref(c.FilePath),
+			ChunkID:  deref(c.ChunkID),
+			Content:  deref(c.Content),
+		})
+	}
+	return docs
+}
+
+// searchQueries collects the executed queries from the retrieved documents,
+// falling back to the intent, which the service sends as a JSON array of
+// query strings.
+func searchQueries(ctx *azopenai.AzureChatExtensionsMessageContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	var queries []string
+	seen := map[string]bool{}
+	for _, d := range ctx.AllRetrievedDocuments {
+		for _, q := range d.SearchQueries {
+			if !seen[q] {
+				seen[q] = true
+				queries = append(queries, q)
+			}
+		}
+	}
+	if len(queries) > 0 || ctx.Intent == nil {
+		return queries
+	}
+	if err := json.Unmarshal([]byte(*ctx.Intent), &queries); err != nil {
+		return nil
+	}
+	return queries
+}
+
+func partialSearch(ctx *azopenai.AzureChatExtensionsMessageContext) bool {
+	if ctx == nil || ctx.Intent == nil || len(ctx.AllRetrievedDocuments) == 0 {
+		return false
+	}
+	var intent []string
+	if err := json.Unmarshal([]byte(*ctx.Intent), &intent); err != nil {
+		return false
+	}
+	answered := map[string]bool{}
+	for _, d := range ctx.AllRetrievedDocuments {
+		for _, q := range d.SearchQueries {
+			answered[q] = true
+		}
+	}
+	for _, q := range intent {
+		if !answered[q] {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentsMessage turns previously retrieved documents into a system message
+// so a follow-up question can be answered from them without another search.
+// It returns nil when there are no documents.
+func DocumentsMessage(docs []RetrievedDocument) azopenai.ChatRequestMessageClassification {
+	if len(docs) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("Answer using only the following documents:\n")
+	for i, d := range docs {
+		fmt.Fprintf(&b, "\n[doc%d]", i+1)
+		if d.Title != "" {
+			fmt.Fprintf(&b, " %s", d.Title)
+		}
+		fmt.Fprintf(&b, "\n%s\n", d.Content)
+	}
+	return &azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(b.String())}
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
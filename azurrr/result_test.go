@@ -0,0 +1,117 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"reflect"
+	"testing"
+)
+
+func TestNewCompletionResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		resp  *test.ResponseBuilder
+		check func(*testing.T, CompletionResult)
+	}{
+		{
+			name: "grounded answer",
+			resp: test.NewResponse().
+				Content("Paris is the capital [doc1].").
+				Citation("France", "https://example.com/fr", "Paris is ...").
+				Intent("capital of France").
+				Usage(120, 8),
+			check: func(t *testing.T, r CompletionResult) {
+				if !r.Grounded || r.Refused {
+					t.Errorf("Grounded = %v, Refused = %v, want true and false", r.Grounded, r.Refused)
+				}
+				want := []Citation{{Title: "France", URL: "https://example.com/fr", Content: "Paris is ..."}}
+				if !reflect.DeepEqual(r.Citations, want) {
+					t.Errorf("Citations = %+v, want %+v", r.Citations, want)
+				}
+				if len(r.RetrievedDocuments) != 1 || r.RetrievedDocuments[0].Title != "France" {
+					t.Errorf("RetrievedDocuments = %+v, want the citation", r.RetrievedDocuments)
+				}
+				if !reflect.DeepEqual(r.SearchQueries, []string{"capital of France"}) {
+					t.Errorf("SearchQueries = %q, want the intent", r.SearchQueries)
+				}
+				if r.Usage != (Usage{PromptTokens: 120, CompletionTokens: 8, TotalTokens: 128}) {
+					t.Errorf("Usage = %+v", r.Usage)
+				}
+				if r.Role != ChatRole(azopenai.ChatRoleAssistant) || r.FinishReason != "stop" || r.ID != "chatcmpl-test" {
+					t.Errorf("Role = %q, FinishReason = %q, ID = %q", r.Role, r.FinishReason, r.ID)
+				}
+			},
+		},
+		{
+			name: "model refusal",
+			resp: test.NewResponse().Refusal("I can't help with that."),
+			check: func(t *testing.T, r CompletionResult) {
+				if r.Grounded || !r.Refused {
+					t.Errorf("Grounded = %v, Refused = %v, want false and true", r.Grounded, r.Refused)
+				}
+			},
+		},
+		{
+			name: "canned out of scope reply",
+			resp: test.NewResponse().Content("The requested information is not available in the retrieved data. Please try another query."),
+			check: func(t *testing.T, r CompletionResult) {
+				if !r.Refused {
+					t.Error("Refused = false, want true")
+				}
+			},
+		},
+		{
+			name: "content filtered",
+			resp: test.NewResponse().FinishReason(azopenai.CompletionsFinishReasonContentFiltered),
+			check: func(t *testing.T, r CompletionResult) {
+				if !r.Refused {
+					t.Error("Refused = false, want true")
+				}
+			},
+		},
+		{
+			name: "ungrounded answer",
+			resp: test.NewResponse().Content("Paris."),
+			check: func(t *testing.T, r CompletionResult) {
+				if r.Grounded || r.Refused || r.Citations != nil || r.SearchQueries != nil {
+					t.Errorf("got %+v, want a plain answer", r)
+				}
+			},
+		},
+		{
+			name: "partial search",
+			resp: test.NewResponse().
+				Content("Paris [doc1].").
+				Citation("France", "https://example.com/fr", "Paris is ...").
+				RetrievedDocument(0, "France", "Paris is ...", "capital of France").
+				Intent("capital of France", "population of Paris"),
+			check: func(t *testing.T, r CompletionResult) {
+				if !r.PartialSearch {
+					t.Error("PartialSearch = false, want true")
+				}
+				if !reflect.DeepEqual(r.SearchQueries, []string{"capital of France"}) {
+					t.Errorf("SearchQueries = %q, want the executed query", r.SearchQueries)
+				}
+				if len(r.RetrievedDocuments) != 1 || r.RetrievedDocuments[0].Content != "Paris is ..." {
+					t.Errorf("RetrievedDocuments = %+v, want the retrieved document", r.RetrievedDocuments)
+				}
+			},
+		},
+		{
+			name: "complete search",
+			resp: test.NewResponse().
+				RetrievedDocument(0, "France", "Paris is ...", "capital of France").
+				Intent("capital of France"),
+			check: func(t *testing.T, r CompletionResult) {
+				if r.PartialSearch {
+					t.Error("PartialSearch = true, want false")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, newCompletionResult(tt.resp.Build()))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"net/http"
+	"time"
+)
+
+// RetryStats reports the retries the package wrapper performed for a call.
+// Retries done inside the SDK pipeline are not counted.
+type RetryStats struct {
+	Retries int
+	// Wait is the total backoff slept before the final attempt.
+	Wait time.Duration
+}
+
+// withRetry calls fn until it succeeds, returns a non-retryable error, or
+// cfg.MaxRetries retries have been spent, doubling the delay each time.
+func (c *Client) withRetry(ctx context.Context, fn func() error) (RetryStats, error) {
+	var stats RetryStats
+	delay := c.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.cfg.MaxRetries || !retryable(err) {
+			return stats, err
+		}
+		if err := c.clock.Sleep(ctx, delay); err != nil {
+			return stats, err
+		}
+		stats.Retries++
+		stats.Wait += delay
+		delay *= 2
+		if delay > c.cfg.MaxRetryDelay {
+			delay = c.cfg.MaxRetryDelay
+		}
+	}
+}
+
+func retryable(err error) bool {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	switch respErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package azurrr
+
+import (
+	"encoding/json"
+)
+
+// ChatRole is the author of a message. The zero value means the service did
+// not report a role.
+type ChatRole string
+
+const (
+	RoleSystem    ChatRole = "system"
+	RoleUser      ChatRole = "user"
+	RoleAssistant ChatRole = "assistant"
+	RoleTool      ChatRole = "tool"
+	// RoleDeveloper carries instructions that newer models rank above the
+	// user's but below the platform's. Deployments that do not know it get
+	// a system message instead; see Config.DeveloperRole.
+	RoleDeveloper ChatRole = "developer"
+)
+
+func (r ChatRole) String() string { return string(r) }
+
+// MarshalJSON encodes an unknown role as null rather than "".
+func (r ChatRole) MarshalJSON() ([]byte, error) {
+	if r == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(string(r))
+}
+
+func (r *ChatRole) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*r = ChatRole(deref(s))
+	return nil
+}
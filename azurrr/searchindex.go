@@ -0,0 +1,129 @@
+package azurrr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// searchAPIVersion is the Azure AI Search data plane version used to read
+// index definitions.
+const searchAPIVersion = "2023-11-01"
+
+// defaultFilePathField is the FilePathField default in the envconfig tag.
+const defaultFilePathField = "filepath"
+
+// searchIndex is the part of an index definition ValidateIndex checks.
+type searchIndex struct {
+	Fields []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"fields"`
+	Semantic *struct {
+		Configurations []struct {
+			Name string `json:"name"`
+		} `json:"configurations"`
+	} `json:"semantic"`
+}
+
+// ValidateIndex reads the configured search index and reports, in one
+// error, every field or setting the configured On Your Data options need
+// but the index lacks: a FilePathField changed from its default, a vector
+// field for vector query types and the semantic configuration for semantic
+// query types. It authenticates with SearchAPIKey or SearchAccessToken as
+// SearchAuth says; the managed identity methods name the OpenAI resource's
+// identity, which the caller cannot act as, so they are reported as
+// unsupported. It returns nil when no search endpoint is configured.
+//
+// transport sends the request; pass the ClientOptions.Transport the client
+// uses, or the NewTransport result, so proxy and TLS settings match the
+// real calls. Nil uses http.DefaultClient.
+func ValidateIndex(ctx context.Context, cfg Config, transport policy.Transporter) error {
+	if cfg.SearchEndpoint == "" {
+		return nil
+	}
+	if cfg.SearchIndex == "" {
+		return errors.New("azurrr: SEARCH_ENDPOINT is set but SEARCH_INDEX_NAME is not")
+	}
+	if err := cfg.checkSearchAuth(); err != nil {
+		return err
+	}
+	if cfg.SearchAuth == AuthSystemManagedIdentity || cfg.SearchAuth == AuthUserManagedIdentity {
+		return fmt.Errorf("azurrr: ValidateIndex does not support SEARCH_AUTH_TYPE %q", cfg.SearchAuth)
+	}
+	u, err := url.Parse(strings.TrimRight(cfg.SearchEndpoint, "/") + "/indexes/" + url.PathEscape(cfg.SearchIndex))
+	if err != nil {
+		return fmt.Errorf("azurrr: invalid search endpoint: %w", err)
+	}
+	u.RawQuery = url.Values{"api-version": {searchAPIVersion}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if cfg.SearchAuth == AuthAccessToken {
+		req.Header.Set("Authorization", "Bearer "+cfg.SearchAccessToken)
+	} else {
+		req.Header.Set("api-key", cfg.SearchAPIKey)
+	}
+	if transport == nil {
+		transport = http.DefaultClient
+	}
+	resp, err := transport.Do(req)
+	if err != nil {
+		return fmt.Errorf("azurrr: reading search index %q: %w", cfg.SearchIndex, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("azurrr: search index %q does not exist", cfg.SearchIndex)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("azurrr: reading search index %q: %s", cfg.SearchIndex, resp.Status)
+	}
+	var index searchIndex
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return fmt.Errorf("azurrr: decoding search index %q: %w", cfg.SearchIndex, err)
+	}
+	return index.check(cfg)
+}
+
+func (index searchIndex) check(cfg Config) error {
+	var problems []string
+	fields := map[string]string{}
+	hasVector := false
+	for _, f := range index.Fields {
+		fields[f.Name] = f.Type
+		if f.Type == "Collection(Edm.Single)" {
+			hasVector = true
+		}
+	}
+	// The file path field is only used by Params.FileFilter, so the default
+	// name is not required to exist.
+	if _, ok := fields[cfg.FilePathField]; !ok && cfg.FilePathField != "" && cfg.FilePathField != defaultFilePathField {
+		problems = append(problems, fmt.Sprintf("no field %q for SEARCH_FILEPATH_FIELD", cfg.FilePathField))
+	}
+	queryType := azopenai.AzureSearchQueryType(cfg.SearchQueryType)
+	if isVectorQueryType(queryType) && !hasVector {
+		problems = append(problems, fmt.Sprintf("query type %q needs a vector field (Collection(Edm.Single)) but the index has none", cfg.SearchQueryType))
+	}
+	if queryType == azopenai.AzureSearchQueryTypeSemantic || queryType == azopenai.AzureSearchQueryTypeVectorSemanticHybrid {
+		found := false
+		if index.Semantic != nil {
+			for _, sc := range index.Semantic.Configurations {
+				found = found || sc.Name == cfg.SemanticConfiguration
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("query type %q needs semantic configuration %q but the index has none by that name", cfg.SearchQueryType, cfg.SemanticConfiguration))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("azurrr: search index %q: %s", cfg.SearchIndex, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package azurrr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func indexServer(t *testing.T, index string, check func(*http.Request)) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(json.RawMessage(index)); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+const vectorIndex = `{"fields":[{"name":"content","type":"Edm.String"},{"name":"contentVector","type":"Collection(Edm.Single)"}]}`
+
+func TestValidateIndexAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		header string
+		want   string
+	}{
+		{"api key", Config{SearchAPIKey: "search-key"}, "api-key", "search-key"},
+		{"access token", Config{SearchAuth: AuthAccessToken, SearchAccessToken: "token"}, "Authorization", "Bearer token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.SearchIndex = "docs"
+			cfg.SearchQueryType = "vector"
+			cfg.SearchEndpoint = indexServer(t, vectorIndex, func(r *http.Request) {
+				if got := r.Header.Get(tt.header); got != tt.want {
+					t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+				}
+			})
+			if err := ValidateIndex(context.Background(), cfg, nil); err != nil {
+				t.Fatalf("ValidateIndex: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateIndexManagedIdentityUnsupported(t *testing.T) {
+	cfg := Config{SearchEndpoint: "https://example.search.windows.net", SearchIndex: "docs", SearchAuth: AuthSystemManagedIdentity}
+	if err := ValidateIndex(context.Background(), cfg, nil); err == nil || !strings.Contains(err.Error(), "does not support") {
+		t.Fatalf("ValidateIndex = %v, want unsupported auth", err)
+	}
+}
+
+func TestValidateIndexFilePathField(t *testing.T) {
+	for _, tt := range []struct {
+		field   string
+		wantErr bool
+	}{
+		{"", false},
+		{defaultFilePathField, false},
+		{"path", true},
+	} {
+		cfg := Config{SearchIndex: "docs", SearchAPIKey: "search-key", SearchQueryType: "vector", FilePathField: tt.field}
+		cfg.SearchEndpoint = indexServer(t, vectorIndex, func(*http.Request) {})
+		err := ValidateIndex(context.Background(), cfg, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FilePathField %q: ValidateIndex = %v, want error %v", tt.field, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateIndexUsesTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, vectorIndex)
+	}))
+	t.Cleanup(srv.Close)
+	cfg := Config{SearchEndpoint: srv.URL, SearchIndex: "docs", SearchAPIKey: "search-key", SearchQueryType: "vector"}
+	if err := ValidateIndex(context.Background(), cfg, srv.Client()); err != nil {
+		t.Fatalf("ValidateIndex with the server's transport: %v", err)
+	}
+	if err := ValidateIndex(context.Background(), cfg, nil); err == nil {
+		t.Fatal("ValidateIndex trusted the test certificate without the transport")
+	}
+}
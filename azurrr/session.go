@@ -0,0 +1,124 @@
+package azurrr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Compaction is how a Session shrinks its history once it exceeds
+// SessionOptions.MaxTokens.
+type Compaction int
+
+const (
+	// CompactionDrop discards the oldest turns.
+	CompactionDrop Compaction = iota
+	// CompactionSummarize replaces the oldest turns with a model-written
+	// summary, which keeps long chats coherent at the cost of an extra call.
+	CompactionSummarize
+)
+
+// SessionOptions contains the optional settings for a Session.
+type SessionOptions struct {
+	// MaxTokens is the estimated size the history may reach before it is
+	// compacted. Zero never compacts.
+	MaxTokens  int
+	Compaction Compaction
+	// SummarizeTurns is how many of the oldest turns CompactionSummarize
+	// folds into one note at a time. Defaults to 4.
+	SummarizeTurns int
+	Params         Params
+}
+
+// Session is a grounded multi-turn conversation. History is compacted
+// before each send when it is over budget. It is not safe for concurrent
+// use.
+type Session struct {
+	client  *Client
+	opts    SessionOptions
+	history []Turn
+}
+
+// NewSession starts an empty conversation.
+func (c *Client) NewSession(opts *SessionOptions) *Session {
+	s := &Session{client: c, opts: SessionOptions{SummarizeTurns: 4}}
+	if opts != nil {
+		s.opts = *opts
+		if s.opts.SummarizeTurns < 2 {
+			s.opts.SummarizeTurns = 4
+		}
+	}
+	return s
+}
+
+// History returns the turns sent so far, after any compaction.
+func (s *Session) History() []Turn {
+	return append([]Turn(nil), s.history...)
+}
+
+// Send adds content as a user turn, sends the conversation and records the
+// answer. On failure the history is left as it was before the call, apart
+// from any compaction already done.
+func (s *Session) Send(ctx context.Context, content string) (CompletionResult, error) {
+	s.history = append(s.history, Turn{Role: string(RoleUser), Content: content})
+	result, err := s.send(ctx)
+	if err != nil {
+		s.history = s.history[:len(s.history)-1]
+		return CompletionResult{}, err
+	}
+	s.history = append(s.history, Turn{Role: string(RoleAssistant), Content: result.Content})
+	return result, nil
+}
+
+func (s *Session) send(ctx context.Context) (CompletionResult, error) {
+	if err := s.compact(ctx); err != nil {
+		return CompletionResult{}, err
+	}
+	turns := append(s.client.instructionTurns(), s.history...)
+	messages, err := ToMessages(turns)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	opts, err := s.client.groundedOptions(messages, s.opts.Params)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	return s.client.getChatCompletions(WithMetadata(ctx, s.opts.Params.Metadata), opts)
+}
+
+// compact shrinks the history until it fits MaxTokens, always keeping the
+// latest turn.
+func (s *Session) compact(ctx context.Context) error {
+	if s.opts.MaxTokens <= 0 {
+		return nil
+	}
+	for len(s.history) > 1 && historyTokens(s.history) > s.opts.MaxTokens {
+		n := min(s.opts.SummarizeTurns, len(s.history)-1)
+		if s.opts.Compaction != CompactionSummarize || n < 2 {
+			s.history = s.history[1:]
+			continue
+		}
+		var b strings.Builder
+		for _, t := range s.history[:n] {
+			fmt.Fprintf(&b, "%s: %s\n", t.Role, t.Content)
+		}
+		summary, err := s.client.Summarize(ctx, b.String(), &SummarizeOptions{
+			Instruction: "Summarize this earlier part of a conversation concisely, keeping facts, decisions and open questions.",
+			Params:      s.opts.Params,
+		})
+		if err != nil {
+			return fmt.Errorf("azurrr: compacting session: %w", err)
+		}
+		note := Turn{Role: string(RoleSystem), Content: "Summary of the earlier conversation: " + summary}
+		s.history = append([]Turn{note}, s.history[n:]...)
+	}
+	return nil
+}
+
+func historyTokens(turns []Turn) int {
+	n := 0
+	for _, t := range turns {
+		n += EstimateTokens(t.Content)
+	}
+	return n
+}
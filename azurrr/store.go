@@ -0,0 +1,120 @@
+package azurrr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// storeAPIVersion is the first api-version with stored completions.
+const storeAPIVersion = "2025-02-01-preview"
+
+// tokenScope is the Entra ID scope of Azure OpenAI, as the SDK requests it.
+const tokenScope = "https://cognitiveservices.azure.com/.default"
+
+// apiVersionAtLeast compares the dates of two api-version strings, ignoring
+// any -preview suffix.
+func apiVersionAtLeast(version, min string) bool {
+	if len(version) < 10 || len(min) < 10 {
+		return false
+	}
+	return version[:10] >= min[:10]
+}
+
+// storePolicy adds "store": true to chat completions requests. The SDK pins
+// an api-version that predates the field, so requests with an older one are
+// raised to storeAPIVersion. It must run after the SDK's own api-version
+// policy, as a per-retry policy.
+type storePolicy struct{}
+
+func (storePolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if raw.Method != http.MethodPost || !strings.HasSuffix(raw.URL.Path, "/chat/completions") || req.Body() == nil {
+		return req.Next()
+	}
+	data, err := io.ReadAll(req.Body())
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("azurrr: adding store to the request: %w", err)
+	}
+	body["store"] = json.RawMessage("true")
+	if data, err = json.Marshal(body); err != nil {
+		return nil, err
+	}
+	// SetBody replaces Content-Type, so keep any ClientOptions.ContentType
+	// override already applied.
+	contentType := raw.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(data)), contentType); err != nil {
+		return nil, err
+	}
+	q := raw.URL.Query()
+	if !apiVersionAtLeast(q.Get("api-version"), storeAPIVersion) {
+		q.Set("api-version", storeAPIVersion)
+		raw.URL.RawQuery = q.Encode()
+	}
+	return req.Next()
+}
+
+// newRESTPipeline builds the pipeline for the endpoints the SDK does not
+// cover, authenticated like the chat client.
+func newRESTPipeline(cfg Config, cred CredentialProvider, opts *policy.ClientOptions) runtime.Pipeline {
+	var auth policy.Policy
+	if cred != nil {
+		auth = runtime.NewBearerTokenPolicy(cred, []string{tokenScope}, nil)
+	} else {
+		auth = runtime.NewKeyCredentialPolicy(azcore.NewKeyCredential(cfg.APIKey), "api-key", nil)
+	}
+	return runtime.NewPipeline(moduleName, moduleVersion, runtime.PipelineOptions{PerRetry: []policy.Policy{auth}}, opts)
+}
+
+// StoredCompletion fetches a completion kept by the service under
+// STORE_COMPLETIONS, by its CompletionResult.StoredID.
+func (c *Client) StoredCompletion(ctx context.Context, id string) (CompletionResult, error) {
+	if c.cfg.Echo {
+		return CompletionResult{}, errors.New("azurrr: stored completions are not available in echo mode")
+	}
+	if id == "" {
+		return CompletionResult{}, errors.New("azurrr: StoredCompletion needs an ID")
+	}
+	endpoint, err := c.cfg.endpoint()
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	u := strings.TrimRight(endpoint, "/") + "/openai/chat/completions/" + url.PathEscape(id)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, u)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	req.Raw().URL.RawQuery = url.Values{"api-version": {storeAPIVersion}}.Encode()
+	resp, err := c.rest.Do(req)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return CompletionResult{}, runtime.NewResponseError(resp)
+	}
+	var completion azopenai.ChatCompletions
+	if err := runtime.UnmarshalAsJSON(resp, &completion); err != nil {
+		return CompletionResult{}, fmt.Errorf("azurrr: decoding stored completion %q: %w", id, err)
+	}
+	result := newCompletionResult(azopenai.GetChatCompletionsResponse{ChatCompletions: completion})
+	result.StoredID = result.ID
+	return result, nil
+}
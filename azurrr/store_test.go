@@ -0,0 +1,91 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestStoreCompletions(t *testing.T) {
+	resp := test.NewResponse().Content("stored answer").Build()
+	var posted map[string]any
+	var postedVersion string
+	c := newFakeClient(t, Config{MaxTokens: 10, StoreCompletions: true}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/openai/deployments/gpt-4o/chat/completions":
+			postedVersion = r.URL.Query().Get("api-version")
+			_ = json.NewDecoder(r.Body).Decode(&posted)
+		case r.Method == http.MethodGet && r.URL.Path == "/openai/chat/completions/chatcmpl-test":
+			if r.Header.Get("api-key") != "test-key" {
+				t.Errorf("api-key = %q", r.Header.Get("api-key"))
+			}
+			if v := r.URL.Query().Get("api-version"); v != storeAPIVersion {
+				t.Errorf("GET api-version = %q, want %s", v, storeAPIVersion)
+			}
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		respondWith(resp)(w, r)
+	}, nil)
+
+	result, err := c.Complete(context.Background(), userMessages("hi"), Params{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if posted["store"] != true {
+		t.Errorf("store = %v, want true", posted["store"])
+	}
+	if posted["messages"] == nil {
+		t.Errorf("request body lost its messages: %v", posted)
+	}
+	if !apiVersionAtLeast(postedVersion, storeAPIVersion) {
+		t.Errorf("POST api-version = %q, want %s or later", postedVersion, storeAPIVersion)
+	}
+	if result.StoredID != "chatcmpl-test" {
+		t.Fatalf("StoredID = %q, want chatcmpl-test", result.StoredID)
+	}
+
+	stored, err := c.StoredCompletion(context.Background(), result.StoredID)
+	if err != nil {
+		t.Fatalf("StoredCompletion: %v", err)
+	}
+	if stored.Content != "stored answer" || stored.StoredID != result.StoredID {
+		t.Errorf("stored = %+v", stored)
+	}
+}
+
+func TestStoreOffByDefault(t *testing.T) {
+	var posted map[string]any
+	reply := respondWith(test.NewResponse().Content("ok").Build())
+	c := newFakeClient(t, Config{MaxTokens: 10}, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+		reply(w, r)
+	}, nil)
+	result, err := c.Complete(context.Background(), userMessages("hi"), Params{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, ok := posted["store"]; ok || result.StoredID != "" {
+		t.Errorf("store = %v, StoredID = %q, want neither", posted["store"], result.StoredID)
+	}
+}
+
+func TestStoreCompletionsKeepsContentType(t *testing.T) {
+	const contentType = "application/json; charset=utf-8"
+	var got string
+	reply := respondWith(test.NewResponse().Content("ok").Build())
+	c := newFakeClient(t, Config{MaxTokens: 10, StoreCompletions: true}, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Content-Type")
+		reply(w, r)
+	}, &ClientOptions{ContentType: contentType})
+	if _, err := c.Complete(context.Background(), userMessages("hi"), Params{}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got != contentType {
+		t.Errorf("Content-Type = %q, want %q", got, contentType)
+	}
+}
@@ -0,0 +1,303 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// chunkReader is the part of *azopenai.EventReader the stream loop needs.
+type chunkReader interface {
+	Read() (azopenai.ChatCompletions, error)
+}
+
+// StreamOptions contains the optional callbacks for Client.Stream.
+type StreamOptions struct {
+	// OnDelta receives each piece of content as it arrives.
+	OnDelta func(string)
+	// OnCitations receives the citations as soon as a chunk carries them,
+	// typically before any content. It is not called if none arrive.
+	OnCitations func([]Citation)
+	// OnEvent receives every streaming event as a typed Delta, including the
+	// content and citations also passed to OnDelta and OnCitations.
+	OnEvent func(Delta)
+	// IdleTimeout aborts the stream with ErrStreamStalled when no chunk
+	// arrives for this long, e.g. 10 * time.Second, guarding against a
+	// connection that stalls without ever ending. Zero disables it.
+	IdleTimeout time.Duration
+	// Reconnects opts into resuming a stream that fails part way, up to this
+	// many times, by re-sending the conversation with the partial answer and
+	// asking the model to continue. The continuation can repeat or drop a few
+	// tokens at the seam. Only streams that were open and failed with a
+	// network error or a retryable status are resumed; streams that could
+	// not be opened, stall or are cancelled are not.
+	Reconnects int
+	// Transcript, when set, receives the stream as JSON lines for audit
+	// logs: a "delta" record per event and a final "result" record, each
+	// with a timestamp and the content token count so far. Each record is
+	// a single Write. The writer is flushed at the end if it has a Flush()
+	// error method, as *bufio.Writer does, but never closed. A write error
+	// stops the transcript and is returned if the stream itself succeeded.
+	Transcript io.Writer
+}
+
+// ErrStreamStalled is returned by Stream when StreamOptions.IdleTimeout
+// passes without a new chunk. The result holds the content received so far.
+var ErrStreamStalled = errors.New("azurrr: stream stalled")
+
+// ErrInboundStream is returned by Stream when the client has inbound
+// transformers and StreamOptions would expose the raw deltas through
+// OnDelta, OnEvent or Transcript. A transformer sees only whole answers, so
+// it cannot vet text that is shown as it arrives.
+var ErrInboundStream = errors.New("azurrr: inbound transformers cannot be applied to streamed deltas")
+
+// Stream sends messages as a grounded streaming request, reporting progress through
+// opts. If the stream fails part way and is not resumed, the content received
+// so far is returned alongside the error with Partial set.
+func (c *Client) Stream(ctx context.Context, messages []azopenai.ChatRequestMessageClassification, p Params, opts *StreamOptions) (result CompletionResult, err error) {
+	if opts == nil {
+		opts = &StreamOptions{}
+	}
+	if len(c.inbound) > 0 && (opts.OnDelta != nil || opts.OnEvent != nil || opts.Transcript != nil) {
+		return CompletionResult{}, ErrInboundStream
+	}
+	if opts.Transcript != nil {
+		t := &transcript{w: opts.Transcript, clock: c.clock}
+		o := *opts
+		o.OnEvent = t.tee(opts.OnEvent)
+		opts = &o
+		defer func() { err = t.finish(result, err) }()
+	}
+	if messages, err = c.transformMessages(messages); err != nil {
+		return CompletionResult{}, err
+	}
+	ctx, endSpan := c.startSpan(WithMetadata(ctx, p.Metadata), "azurrr.Stream", messages)
+	defer func() { err = endSpan(err) }()
+	if err := c.validate(messages); err != nil {
+		return CompletionResult{}, err
+	}
+	chatOpts, err := c.groundedOptions(messages, p)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	if err := c.cfg.checkTokenBudget(&chatOpts); err != nil {
+		return CompletionResult{}, err
+	}
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	defer release()
+	start := c.clock.Now()
+	result, err = c.streamOnce(ctx, chatOpts, opts, start)
+	last := result
+	for result.Reconnects < opts.Reconnects && err != nil && reconnectable(ctx, last, err) {
+		prev := result
+		cont := chatOpts
+		cont.Messages = continuationMessages(chatOpts.Messages, prev.Content)
+		last, err = c.streamOnce(ctx, cont, opts, start)
+		result = mergeStreams(prev, last)
+	}
+	c.annotate(&result, chatOpts)
+	result.LowConfidence = c.cfg.lowConfidence(chatOpts, result)
+	return c.inboundResult(result, err)
+}
+
+// streamOnce runs one streaming request, under its own idle watchdog.
+func (c *Client) streamOnce(ctx context.Context, chatOpts azopenai.ChatCompletionsOptions, opts *StreamOptions, start time.Time) (CompletionResult, error) {
+	cancel := func() {}
+	if opts.IdleTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+	}
+	r, closeStream, stats, err := c.openStream(ctx, chatOpts)
+	if err != nil {
+		return CompletionResult{Retry: stats}, c.wrapError(ctx, deref(chatOpts.DeploymentName), err)
+	}
+	defer closeStream()
+	var wd *watchdog
+	if opts.IdleTimeout > 0 {
+		wd = newWatchdog(ctx, c.clock, opts.IdleTimeout, cancel)
+		r = wd.wrap(r)
+	}
+	result, err := readStream(r, opts, c.clock, start)
+	limitToolCalls(&result, chatOpts)
+	result.Retry = stats
+	if err != nil && wd != nil && wd.stalled.Load() {
+		err = ErrStreamStalled
+	}
+	return result, err
+}
+
+// reconnectable reports whether a failed stream is worth resuming: the
+// caller has not given up, the stream was open when it failed and the
+// failure looks transient. Streams that could not be opened at all have
+// already been retried by withRetry, and stalls are not resumed.
+func reconnectable(ctx context.Context, last CompletionResult, err error) bool {
+	if ctx.Err() != nil || last.Stream == nil || errors.Is(err, ErrStreamStalled) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return retryable(err) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// mergeStreams appends a reconnected stream's result to the one it resumed.
+func mergeStreams(prev, next CompletionResult) CompletionResult {
+	merged := appendResult(prev, next)
+	merged.Reconnects = prev.Reconnects + 1
+	if next.Stream == nil {
+		// The reconnect itself failed, so prev's content is still all there is.
+		merged.Partial = true
+	}
+	if prev.Stream != nil {
+		if merged.Stream == nil {
+			merged.Stream = prev.Stream
+		} else {
+			m := *merged.Stream
+			m.Tokens += prev.Stream.Tokens
+			if prev.Stream.TimeToFirstToken > 0 {
+				m.TimeToFirstToken = prev.Stream.TimeToFirstToken
+			}
+			if gen := m.Duration - m.TimeToFirstToken; gen > 0 {
+				m.TokensPerSecond = float64(m.Tokens) / gen.Seconds()
+			}
+			merged.Stream = &m
+		}
+	}
+	return merged
+}
+
+// openStream sends the streaming request, or starts the canned echo stream
+// in echo mode. The returned func closes the stream.
+func (c *Client) openStream(ctx context.Context, o azopenai.ChatCompletionsOptions) (chunkReader, func() error, RetryStats, error) {
+	if c.cfg.Echo {
+		return newEchoReader(o.Messages), func() error { return nil }, RetryStats{}, nil
+	}
+	var resp azopenai.GetChatCompletionsStreamResponse
+	stats, err := c.withRetry(ctx, func() (err error) {
+		resp, err = c.chat.GetChatCompletionsStream(ctx, streamOptions(o), nil)
+		return err
+	})
+	if err != nil {
+		return nil, nil, stats, err
+	}
+	return resp.ChatCompletionsStream, resp.ChatCompletionsStream.Close, stats, nil
+}
+
+// watchdog cancels a stream once timeout passes with no chunk read.
+type watchdog struct {
+	clock   Clock
+	last    atomic.Int64 // UnixNano of the latest chunk
+	stalled atomic.Bool
+}
+
+func newWatchdog(ctx context.Context, clock Clock, timeout time.Duration, cancel func()) *watchdog {
+	w := &watchdog{clock: clock}
+	w.touch()
+	go func() {
+		for {
+			wait := timeout - clock.Now().Sub(time.Unix(0, w.last.Load()))
+			if wait <= 0 {
+				w.stalled.Store(true)
+				cancel()
+				return
+			}
+			if clock.Sleep(ctx, wait) != nil {
+				return
+			}
+		}
+	}()
+	return w
+}
+
+func (w *watchdog) touch() { w.last.Store(w.clock.Now().UnixNano()) }
+
+func (w *watchdog) wrap(r chunkReader) chunkReader { return watchedReader{r, w} }
+
+type watchedReader struct {
+	chunkReader
+	w *watchdog
+}
+
+func (r watchedReader) Read() (azopenai.ChatCompletions, error) {
+	chunk, err := r.chunkReader.Read()
+	if err == nil {
+		r.w.touch()
+	}
+	return chunk, err
+}
+
+// StreamMetrics are the latency figures of a streaming call. Tokens counts
+// content deltas, which the service sends one token at a time.
+type StreamMetrics struct {
+	TimeToFirstToken time.Duration
+	Duration         time.Duration
+	Tokens           int
+	// TokensPerSecond is measured from the first token to the end.
+	TokensPerSecond float64
+}
+
+// StartStream runs Stream in the background. wait blocks until the stream
+// ends and returns its result. stop cancels the request, for example when a
+// user hits "stop generating"; it is safe to call repeatedly and after the
+// stream has finished. A stopped stream's result holds the content received
+// so far with Partial set, and its error is context.Canceled.
+func (c *Client) StartStream(ctx context.Context, messages []azopenai.ChatRequestMessageClassification, p Params, opts *StreamOptions) (wait func() (CompletionResult, error), stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var result CompletionResult
+	var err error
+	go func() {
+		defer close(done)
+		defer cancel()
+		result, err = c.Stream(ctx, messages, p, opts)
+		if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+			err = context.Canceled
+		}
+	}()
+	wait = func() (CompletionResult, error) {
+		<-done
+		return result, err
+	}
+	return wait, cancel
+}
+
+// readStream accumulates r into a result. start is when the request was
+// sent, for the latency metrics.
+func readStream(r chunkReader, opts *StreamOptions, clock Clock, start time.Time) (CompletionResult, error) {
+	acc := &StreamAccumulator{onContent: opts.OnDelta, onCitations: opts.OnCitations, onEvent: opts.OnEvent}
+	var first time.Time
+	finish := func() CompletionResult {
+		result := acc.Result()
+		metrics := &StreamMetrics{Tokens: acc.tokens, Duration: clock.Now().Sub(start)}
+		if !first.IsZero() {
+			metrics.TimeToFirstToken = first.Sub(start)
+			if gen := metrics.Duration - metrics.TimeToFirstToken; gen > 0 {
+				metrics.TokensPerSecond = float64(metrics.Tokens) / gen.Seconds()
+			}
+		}
+		result.Stream = metrics
+		return result
+	}
+	for {
+		chunk, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return finish(), nil
+		}
+		if err != nil {
+			result := finish()
+			result.Partial = true
+			return result, err
+		}
+		before := acc.tokens
+		acc.Add(chunk)
+		if first.IsZero() && acc.tokens > before {
+			first = clock.Now()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadStreamKeepsPartialContent(t *testing.T) {
+	chunks := test.NewResponse().Content("Paris is the capital.").Stream()
+	tests := []struct {
+		name        string
+		failAfter   int
+		wantContent string
+		wantPartial bool
+	}{
+		{"complete", 0, "Paris is the capital.", false},
+		{"fails before content", 1, "", true},
+		{"fails mid answer", 3, "Paris is", true},
+		{"fails before finish", len(chunks) - 1, "Paris is the capital.", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &test.ChunkReader{Chunks: chunks, FailAfter: tt.failAfter}
+			result, err := readStream(r, &StreamOptions{}, realClock{}, time.Now())
+			if tt.wantPartial != errors.Is(err, test.ErrInjected) {
+				t.Fatalf("err = %v, want injected error: %v", err, tt.wantPartial)
+			}
+			if result.Partial != tt.wantPartial {
+				t.Errorf("Partial = %v, want %v", result.Partial, tt.wantPartial)
+			}
+			if result.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", result.Content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestStreamDoesNotReconnectFailedOpen(t *testing.T) {
+	var calls atomic.Int32
+	c := newFakeClient(t, Config{MaxTokens: 10}, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":"401","message":"Access denied"}}`)
+	}, nil)
+	result, err := c.Stream(context.Background(), userMessages("hi"), Params{}, &StreamOptions{Reconnects: 3})
+	if err == nil {
+		t.Fatal("Stream succeeded against a 401")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+	if result.Reconnects != 0 || result.Partial {
+		t.Errorf("Reconnects = %d, Partial = %v, want 0 and false", result.Reconnects, result.Partial)
+	}
+}
+
+func TestStreamReconnectsBrokenConnection(t *testing.T) {
+	chunks := test.NewResponse().Content("Paris is the capital.").Stream()
+	var calls atomic.Int32
+	c := newFakeClient(t, Config{MaxTokens: 10}, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			streamWith(test.NewResponse().Content(" of France.").Stream())(w, r)
+			return
+		}
+		// Send the first words, then drop the connection mid-stream.
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks[:3] {
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijacking: %v", err)
+			return
+		}
+		conn.Close()
+	}, nil)
+	result, err := c.Stream(context.Background(), userMessages("hi"), Params{}, &StreamOptions{Reconnects: 2})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if result.Content != "Paris is of France." || result.Reconnects != 1 || result.Partial {
+		t.Errorf("Content = %q, Reconnects = %d, Partial = %v", result.Content, result.Reconnects, result.Partial)
+	}
+}
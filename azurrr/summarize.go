@@ -0,0 +1,111 @@
+package azurrr
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// SummarizeOptions contains the optional settings for Client.Summarize.
+type SummarizeOptions struct {
+	// ChunkTokens is the estimated size of each chunk. Defaults to 2000.
+	ChunkTokens int
+	// OverlapTokens is how much consecutive chunks share so sentences cut at
+	// a boundary are seen whole. Defaults to 200.
+	OverlapTokens int
+	// Instruction is the system prompt for each summarization call.
+	Instruction string
+	Params      Params
+}
+
+const defaultSummarizeInstruction = "Summarize the following text concisely, keeping key facts, names and numbers."
+
+// Summarize condenses text by summarizing token-bounded chunks and then
+// summarizing those summaries. Text that fits in one chunk takes a single
+// call.
+func (c *Client) Summarize(ctx context.Context, text string, opts *SummarizeOptions) (string, error) {
+	o := SummarizeOptions{ChunkTokens: 2000, OverlapTokens: 200, Instruction: defaultSummarizeInstruction}
+	if opts != nil {
+		if opts.ChunkTokens > 0 {
+			o.ChunkTokens = opts.ChunkTokens
+		}
+		if opts.OverlapTokens > 0 {
+			o.OverlapTokens = opts.OverlapTokens
+		}
+		if opts.Instruction != "" {
+			o.Instruction = opts.Instruction
+		}
+		o.Params = opts.Params
+	}
+	if o.OverlapTokens >= o.ChunkTokens {
+		return "", errors.New("azurrr: OverlapTokens must be smaller than ChunkTokens")
+	}
+	chunks := chunkText(text, o.ChunkTokens, o.OverlapTokens)
+	if len(chunks) <= 1 {
+		return c.summarizeOnce(ctx, text, o)
+	}
+	summaries := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		s, err := c.summarizeOnce(ctx, chunk, o)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, s)
+	}
+	// Summaries may still be too long together; recurse until they fit,
+	// unless a round failed to shrink the text at all.
+	joined := strings.Join(summaries, "\n\n")
+	if len(joined) >= len(text) {
+		return c.summarizeOnce(ctx, joined, o)
+	}
+	return c.Summarize(ctx, joined, &o)
+}
+
+func (c *Client) summarizeOnce(ctx context.Context, text string, o SummarizeOptions) (string, error) {
+	messages, err := ToMessages([]Turn{
+		{Role: string(RoleSystem), Content: o.Instruction},
+		{Role: string(RoleUser), Content: text},
+	})
+	if err != nil {
+		return "", err
+	}
+	result, err := c.Complete(ctx, messages, o.Params)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// chunkText splits text on word boundaries into chunks of about chunkTokens
+// estimated tokens, each starting overlapTokens before the previous one ended.
+func chunkText(text string, chunkTokens, overlapTokens int) []string {
+	words := strings.Fields(text)
+	var chunks []string
+	for start := 0; start < len(words); {
+		end, tokens := start, 0
+		for end < len(words) {
+			t := EstimateTokens(words[end]) + 1
+			if tokens+t > chunkTokens && end > start {
+				break
+			}
+			tokens += t
+			end++
+		}
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+		// Walk back from end to start the next chunk inside the overlap.
+		next, overlap := end, 0
+		for next > start+1 {
+			t := EstimateTokens(words[next-1]) + 1
+			if overlap+t > overlapTokens {
+				break
+			}
+			overlap += t
+			next--
+		}
+		start = next
+	}
+	return chunks
+}
@@ -0,0 +1,61 @@
+package azurrr
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"unicode/utf8"
+)
+
+// charsPerToken is the usual rule of thumb for English text with the GPT
+// tokenizers. Estimates are deliberately conservative approximations.
+const charsPerToken = 4
+
+// EstimateTokens approximates the number of tokens in s without a tokenizer.
+func EstimateTokens(s string) int {
+	n := utf8.RuneCountInString(s)
+	return (n + charsPerToken - 1) / charsPerToken
+}
+
+// messageOverheadTokens approximates the role and separator tokens the chat
+// format adds to each message.
+const messageOverheadTokens = 4
+
+// estimatePromptTokens approximates the prompt size of messages. Documents
+// On Your Data adds to the prompt are not counted.
+func estimatePromptTokens(messages []azopenai.ChatRequestMessageClassification) int {
+	n := 0
+	for _, m := range messages {
+		_, text := messageText(m)
+		n += EstimateTokens(text) + messageOverheadTokens
+	}
+	return n
+}
+
+// checkTokenBudget compares opts.MaxTokens with the room the estimated
+// prompt leaves in the deployment's context window, when that window is
+// configured in DeploymentContextTokens. It clamps MaxTokens instead of
+// failing under ClampMaxTokens.
+func (c Config) checkTokenBudget(opts *azopenai.ChatCompletionsOptions) error {
+	if opts.MaxTokens == nil {
+		return nil
+	}
+	window, ok := c.DeploymentContextTokens[deref(opts.DeploymentName)]
+	if !ok {
+		return nil
+	}
+	prompt := estimatePromptTokens(opts.Messages)
+	budget := int(window) - prompt
+	if int(*opts.MaxTokens) <= budget {
+		return nil
+	}
+	if c.ClampMaxTokens && budget > 0 {
+		clamped := int32(budget)
+		opts.MaxTokens = &clamped
+		return nil
+	}
+	return &ErrMaxTokensTooLarge{
+		MaxTokens:     int(*opts.MaxTokens),
+		PromptTokens:  prompt,
+		ContextTokens: int(window),
+		Budget:        max(budget, 0),
+	}
+}
@@ -0,0 +1,80 @@
+package azurrr
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// ToolHandler executes a tool call and returns its output for the model.
+type ToolHandler func(ctx context.Context, call ToolCall) (string, error)
+
+// defaultMaxToolRounds applies when Config.MaxToolRounds is zero, as in a
+// Config built in code rather than loaded from the environment.
+const defaultMaxToolRounds = 5
+
+// ErrToolLoopExceeded is returned by RunTools when the model still asks for
+// tools after Config.MaxToolRounds rounds. Messages is the conversation so
+// far, including every tool call and output.
+type ErrToolLoopExceeded struct {
+	Rounds   int
+	Messages []azopenai.ChatRequestMessageClassification
+}
+
+func (e *ErrToolLoopExceeded) Error() string {
+	return fmt.Sprintf("azurrr: model still requesting tools after %d rounds", e.Rounds)
+}
+
+// RunTools completes messages, executing the requested tool calls with
+// handle and sending their outputs back until the model answers without
+// tools. p.Tools must describe the tools handle understands. The result's
+// ToolRounds is the number of rounds of tool calls executed.
+func (c *Client) RunTools(ctx context.Context, messages []azopenai.ChatRequestMessageClassification, p Params, handle ToolHandler) (CompletionResult, error) {
+	msgs := append([]azopenai.ChatRequestMessageClassification(nil), messages...)
+	for round := 0; ; round++ {
+		result, err := c.Complete(ctx, msgs, p)
+		result.ToolRounds = round
+		if err != nil || len(result.ToolCalls) == 0 {
+			return result, err
+		}
+		if round >= c.cfg.maxToolRounds() {
+			return result, &ErrToolLoopExceeded{Rounds: round, Messages: msgs}
+		}
+		msgs = append(msgs, toolCallMessage(result.ToolCalls))
+		for _, call := range result.ToolCalls {
+			out, err := handle(ctx, call)
+			if err != nil {
+				return result, fmt.Errorf("azurrr: tool %s: %w", call.Name, err)
+			}
+			msgs = append(msgs, &azopenai.ChatRequestToolMessage{
+				Content:    azopenai.NewChatRequestToolMessageContent(out),
+				ToolCallID: to.Ptr(call.ID),
+			})
+		}
+	}
+}
+
+func (c Config) maxToolRounds() int {
+	if c.MaxToolRounds <= 0 {
+		return defaultMaxToolRounds
+	}
+	return c.MaxToolRounds
+}
+
+// toolCallMessage echoes the model's tool calls back as the assistant turn
+// the tool outputs answer.
+func toolCallMessage(calls []ToolCall) *azopenai.ChatRequestAssistantMessage {
+	msg := &azopenai.ChatRequestAssistantMessage{}
+	for _, call := range calls {
+		msg.ToolCalls = append(msg.ToolCalls, &azopenai.ChatCompletionsFunctionToolCall{
+			ID:   to.Ptr(call.ID),
+			Type: to.Ptr("function"),
+			Function: &azopenai.FunctionCall{
+				Name:      to.Ptr(call.Name),
+				Arguments: to.Ptr(call.Arguments),
+			},
+		})
+	}
+	return msg
+}
@@ -0,0 +1,112 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"context"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"net/http"
+	"testing"
+)
+
+// toolCallResponse asks for one call of the lookup tool.
+func toolCallResponse() azopenai.GetChatCompletionsResponse {
+	resp := test.NewResponse().FinishReason(azopenai.CompletionsFinishReasonToolCalls).Build()
+	resp.Choices[0].Message.Content = nil
+	resp.Choices[0].Message.ToolCalls = []azopenai.ChatCompletionsToolCallClassification{
+		&azopenai.ChatCompletionsFunctionToolCall{
+			ID:       to.Ptr("call-1"),
+			Type:     to.Ptr("function"),
+			Function: &azopenai.FunctionCall{Name: to.Ptr("lookup"), Arguments: to.Ptr(`{"q":"x"}`)},
+		},
+	}
+	return resp
+}
+
+// toolServer asks for tools for the first rounds requests, then answers.
+func toolServer(rounds int) http.HandlerFunc {
+	calls := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= rounds {
+			respondWith(toolCallResponse())(w, r)
+			return
+		}
+		respondWith(test.NewResponse().Content("done").Build())(w, r)
+	}
+}
+
+func TestRunTools(t *testing.T) {
+	lookup := func(ctx context.Context, call ToolCall) (string, error) { return "result", nil }
+	tests := []struct {
+		name       string
+		maxRounds  int
+		toolRounds int
+		wantErr    bool
+	}{
+		{"zero config defaults to 5 rounds", 0, 2, false},
+		{"within the default", 0, 5, false},
+		{"beyond the default", 0, 6, true},
+		{"configured limit", 1, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeClient(t, Config{MaxTokens: 10, MaxToolRounds: tt.maxRounds}, toolServer(tt.toolRounds), nil)
+			result, err := c.RunTools(context.Background(), userMessages("hi"), Params{}, lookup)
+			var exceeded *ErrToolLoopExceeded
+			if tt.wantErr != errors.As(err, &exceeded) {
+				t.Fatalf("err = %v, want loop exceeded: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (result.Content != "done" || result.ToolRounds != tt.toolRounds) {
+				t.Errorf("Content = %q, ToolRounds = %d, want done after %d", result.Content, result.ToolRounds, tt.toolRounds)
+			}
+		})
+	}
+}
+
+func TestStreamHonorsParallelToolCalls(t *testing.T) {
+	call := func(id string) azopenai.ChatCompletions {
+		return azopenai.ChatCompletions{Choices: []azopenai.ChatChoice{{
+			Index: to.Ptr[int32](0),
+			Delta: &azopenai.ChatResponseMessage{ToolCalls: []azopenai.ChatCompletionsToolCallClassification{
+				&azopenai.ChatCompletionsFunctionToolCall{
+					ID:       to.Ptr(id),
+					Type:     to.Ptr("function"),
+					Function: &azopenai.FunctionCall{Name: to.Ptr("lookup"), Arguments: to.Ptr(`{}`)},
+				},
+			}},
+		}}}
+	}
+	chunks := []azopenai.ChatCompletions{call("call-1"), call("call-2"), {Choices: []azopenai.ChatChoice{{
+		Index:        to.Ptr[int32](0),
+		FinishReason: to.Ptr(azopenai.CompletionsFinishReasonToolCalls),
+	}}}}
+	tests := []struct {
+		name     string
+		parallel *bool
+		want     int
+	}{
+		{"service default", nil, 2},
+		{"parallel", to.Ptr(true), 2},
+		{"not parallel", to.Ptr(false), 1},
+	}
+	tools := []azopenai.ChatCompletionsToolDefinitionClassification{
+		&azopenai.ChatCompletionsFunctionToolDefinition{
+			Type:     to.Ptr("function"),
+			Function: &azopenai.ChatCompletionsFunctionToolDefinitionFunction{Name: to.Ptr("lookup")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeClient(t, Config{MaxTokens: 10}, streamWith(chunks), nil)
+			result, err := c.Stream(context.Background(), userMessages("hi"), Params{Tools: tools, ParallelToolCalls: tt.parallel}, nil)
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			if len(result.ToolCalls) != tt.want {
+				t.Errorf("got %d tool calls, want %d", len(result.ToolCalls), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package azurrr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// startSpan opens a span for an outgoing call and, with cfg.LogRequests,
+// logs the same summary. Only message counts and roles are recorded unless
+// cfg.TraceContent is set, so system prompts do not leak into telemetry.
+// The call's metadata is recorded too, and the returned func wraps a
+// failure in a *MetadataError when there is any.
+func (c *Client) startSpan(ctx context.Context, name string, messages []azopenai.ChatRequestMessageClassification) (context.Context, func(error) error) {
+	attrs := c.messageAttributes(messages)
+	md := metadataFrom(ctx)
+	for _, k := range slices.Sorted(maps.Keys(md)) {
+		attrs = append(attrs, tracing.Attribute{Key: "azurrr.metadata." + k, Value: md[k]})
+	}
+	ctx, span := c.tracer.Start(ctx, name, &tracing.SpanOptions{
+		Kind:       tracing.SpanKindClient,
+		Attributes: attrs,
+	})
+	if c.cfg.LogRequests {
+		log.Printf("%s %s", name, formatAttributes(attrs))
+	}
+	return ctx, func(err error) error {
+		if err != nil {
+			span.SetStatus(tracing.SpanStatusError, err.Error())
+		}
+		span.End()
+		var mdErr *MetadataError
+		if err != nil && len(md) > 0 && !errors.As(err, &mdErr) {
+			err = &MetadataError{Metadata: md, Err: err}
+		}
+		return err
+	}
+}
+
+func (c *Client) messageAttributes(messages []azopenai.ChatRequestMessageClassification) []tracing.Attribute {
+	type entry struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	roles := make([]string, len(messages))
+	entries := make([]entry, len(messages))
+	for i, m := range messages {
+		role, text := messageText(m)
+		roles[i] = role
+		entries[i] = entry{Role: role, Content: text}
+	}
+	attrs := []tracing.Attribute{
+		{Key: "azurrr.deployment", Value: c.cfg.Deployment},
+		{Key: "azurrr.message_count", Value: len(messages)},
+		{Key: "azurrr.message_roles", Value: strings.Join(roles, ",")},
+	}
+	if c.cfg.TraceContent {
+		data, _ := json.Marshal(entries)
+		attrs = append(attrs, tracing.Attribute{Key: "azurrr.messages", Value: string(data)})
+	}
+	return attrs
+}
+
+func formatAttributes(attrs []tracing.Attribute) string {
+	parts := make([]string, len(attrs))
+	for i, a := range attrs {
+		parts[i] = fmt.Sprintf("%s=%v", a.Key, a.Value)
+	}
+	return strings.Join(parts, " ")
+}
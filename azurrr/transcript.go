@@ -0,0 +1,73 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// transcriptRecord is one JSONL line of a stream transcript: a "delta"
+// record per event, then one "result" record.
+type transcriptRecord struct {
+	Time   time.Time         `json:"time"`
+	Type   string            `json:"type"`
+	Delta  *Delta            `json:"delta,omitempty"`
+	Tokens int               `json:"tokens"`
+	Result *CompletionResult `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// transcript tees stream events to StreamOptions.Transcript. After the
+// first write error it stops writing and reports that error at the end.
+type transcript struct {
+	w      io.Writer
+	clock  Clock
+	tokens int
+	err    error
+}
+
+func (t *transcript) write(rec transcriptRecord) {
+	if t.err != nil {
+		return
+	}
+	rec.Time = t.clock.Now().UTC()
+	rec.Tokens = t.tokens
+	line, err := json.Marshal(rec)
+	if err != nil {
+		t.err = err
+		return
+	}
+	_, t.err = t.w.Write(append(line, '\n'))
+}
+
+// tee records each event before passing it on to next.
+func (t *transcript) tee(next func(Delta)) func(Delta) {
+	return func(d Delta) {
+		if d.Content != "" {
+			t.tokens++
+		}
+		t.write(transcriptRecord{Type: "delta", Delta: &d})
+		if next != nil {
+			next(d)
+		}
+	}
+}
+
+// finish writes the result record, flushes a writer that supports it and
+// returns streamErr, or the transcript's own error when the stream itself
+// succeeded.
+func (t *transcript) finish(result CompletionResult, streamErr error) error {
+	rec := transcriptRecord{Type: "result", Result: &result}
+	if streamErr != nil {
+		rec.Error = streamErr.Error()
+	}
+	t.write(rec)
+	if f, ok := t.w.(interface{ Flush() error }); ok && t.err == nil {
+		t.err = f.Flush()
+	}
+	if streamErr == nil && t.err != nil {
+		return fmt.Errorf("azurrr: writing transcript: %w", t.err)
+	}
+	return streamErr
+}
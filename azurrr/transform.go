@@ -0,0 +1,117 @@
+package azurrr
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// Transformer rewrites message content, for example to scrub personal data
+// or expand templates. An error aborts the call.
+type Transformer func(string) (string, error)
+
+// transformMessages runs the outbound transformers over the text content of
+// messages. Multi-part content, such as a user message with images, is
+// passed through untouched.
+func (c *Client) transformMessages(messages []azopenai.ChatRequestMessageClassification) ([]azopenai.ChatRequestMessageClassification, error) {
+	if len(c.outbound) == 0 {
+		return messages, nil
+	}
+	out := make([]azopenai.ChatRequestMessageClassification, len(messages))
+	for i, m := range messages {
+		text, ok := plainContent(m)
+		if !ok {
+			out[i] = m
+			continue
+		}
+		text, err := runTransformers(c.outbound, text)
+		if err != nil {
+			return nil, fmt.Errorf("azurrr: outbound transformer: %w", err)
+		}
+		out[i] = withContent(m, text)
+	}
+	return out, nil
+}
+
+// transformResult runs the inbound transformers over the answer.
+func (c *Client) transformResult(r *CompletionResult) error {
+	if len(c.inbound) == 0 {
+		return nil
+	}
+	content, err := runTransformers(c.inbound, r.Content)
+	if err != nil {
+		return fmt.Errorf("azurrr: inbound transformer: %w", err)
+	}
+	r.Content = content
+	return nil
+}
+
+// inboundResult applies the inbound transformers to a call's result. A
+// partial result returned with an error is transformed too, and loses its
+// content if that fails, so untransformed text never reaches the caller.
+func (c *Client) inboundResult(result CompletionResult, err error) (CompletionResult, error) {
+	terr := c.transformResult(&result)
+	switch {
+	case terr == nil:
+		return result, err
+	case err != nil:
+		result.Content = ""
+		return result, err
+	}
+	return CompletionResult{}, terr
+}
+
+func runTransformers(ts []Transformer, s string) (string, error) {
+	for _, t := range ts {
+		var err error
+		if s, err = t(s); err != nil {
+			return "", err
+		}
+	}
+	return s, nil
+}
+
+// plainContent returns the content of a message whose content is a single
+// string.
+func plainContent(m azopenai.ChatRequestMessageClassification) (string, bool) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", false
+	}
+	var wire struct {
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return "", false
+	}
+	var text string
+	if err := json.Unmarshal(wire.Content, &text); err != nil {
+		return "", false
+	}
+	return text, true
+}
+
+// withContent returns a copy of m with its content replaced by text.
+func withContent(m azopenai.ChatRequestMessageClassification, text string) azopenai.ChatRequestMessageClassification {
+	switch m := m.(type) {
+	case *azopenai.ChatRequestSystemMessage:
+		cp := *m
+		cp.Content = azopenai.NewChatRequestSystemMessageContent(text)
+		return &cp
+	case *azopenai.ChatRequestUserMessage:
+		cp := *m
+		cp.Content = azopenai.NewChatRequestUserMessageContent(text)
+		return &cp
+	case *azopenai.ChatRequestAssistantMessage:
+		cp := *m
+		cp.Content = azopenai.NewChatRequestAssistantMessageContent(text)
+		return &cp
+	case *azopenai.ChatRequestToolMessage:
+		cp := *m
+		cp.Content = azopenai.NewChatRequestToolMessageContent(text)
+		return &cp
+	case *developerMessage:
+		return &developerMessage{Content: text}
+	}
+	return m
+}
@@ -0,0 +1,39 @@
+package azurrr
+
+import (
+	"azurePavel/internal/test"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInboundTransformersRefuseStreamedDeltas(t *testing.T) {
+	upper := func(s string) (string, error) { return strings.ToUpper(s), nil }
+	handler := streamWith(test.NewResponse().Content("hello there").Stream())
+	c := newFakeClient(t, Config{MaxTokens: 10}, handler, &ClientOptions{Inbound: []Transformer{upper}})
+	for name, opts := range map[string]*StreamOptions{
+		"OnDelta":    {OnDelta: func(string) {}},
+		"OnEvent":    {OnEvent: func(Delta) {}},
+		"Transcript": {Transcript: &bytes.Buffer{}},
+	} {
+		if _, err := c.Stream(context.Background(), userMessages("hi"), Params{}, opts); !errors.Is(err, ErrInboundStream) {
+			t.Errorf("%s: Stream error = %v, want ErrInboundStream", name, err)
+		}
+	}
+	b, err := NewBroadcast(BroadcastBlock, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.StreamBroadcast(context.Background(), b, userMessages("hi"), Params{}, nil); !errors.Is(err, ErrInboundStream) {
+		t.Errorf("StreamBroadcast error = %v, want ErrInboundStream", err)
+	}
+	result, err := c.Stream(context.Background(), userMessages("hi"), Params{}, nil)
+	if err != nil {
+		t.Fatalf("Stream without callbacks: %v", err)
+	}
+	if result.Content != "HELLO THERE" {
+		t.Errorf("Content = %q, want the transformed answer", result.Content)
+	}
+}
@@ -0,0 +1,41 @@
+package azurrr
+
+import (
+	"net/http"
+	"time"
+)
+
+// ConnectionOptions tunes how connections to the Azure endpoint are reused.
+// Zero fields take the defaults below. Go's own default of two idle
+// connections per host is too few for a busy service, which then opens and
+// closes a TLS connection for most calls.
+type ConnectionOptions struct {
+	// MaxIdleConns caps idle connections across all hosts. Defaults to 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections to one host, which is
+	// normally all of them. Defaults to 100.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes connections idle for this long. Defaults to
+	// 90 seconds, below the Azure front end's idle limit.
+	IdleConnTimeout time.Duration
+}
+
+// NewTransport builds an HTTP client with o's connection settings, for
+// ClientOptions.Transport. NewClient does this itself when
+// ClientOptions.Connections is set.
+func NewTransport(o ConnectionOptions) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 100
+	t.IdleConnTimeout = 90 * time.Second
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	return &http.Client{Transport: t}
+}
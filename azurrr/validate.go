@@ -0,0 +1,75 @@
+package azurrr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Validate checks the whole configuration in one pass and returns every
+// problem it finds joined into one error, each naming the settings at
+// fault, instead of stopping at the first. It requires APIKey; NewClient
+// skips that when a token credential is given, and ConfigFromFile leaves it
+// to NewClient.
+func (c Config) Validate() error {
+	return c.validate(true)
+}
+
+func (c Config) validate(needKey bool) error {
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	add(c.validateRequired(needKey))
+	add(c.checkConflicts())
+	_, err := c.endpoint()
+	add(err)
+	if c.SearchEndpoint != "" && c.SearchIndex == "" {
+		add(errors.New("azurrr: SEARCH_ENDPOINT is set but SEARCH_INDEX_NAME is not"))
+	}
+	if c.SearchFilter != "" && strings.TrimSpace(c.SearchFilter) == "" {
+		add(errors.New("azurrr: SEARCH_FILTER is set but empty"))
+	}
+	if c.EmbeddingAPIVersion != "" && c.EmbeddingEndpoint == "" {
+		add(errors.New("azurrr: EMBEDDING_API_VERSION is set but EMBEDDING_ENDPOINT is not"))
+	}
+	add(c.checkEmbedding())
+	if c.EmbeddingCacheSize < 0 || c.EmbeddingCacheTTL < 0 {
+		add(errors.New("azurrr: EMBEDDING_CACHE_SIZE and EMBEDDING_CACHE_TTL must not be negative"))
+	}
+	add(c.checkSearchAuth())
+	add(c.checkEmbeddingAuth())
+	_, err = c.enhancements()
+	add(err)
+	add(c.checkScopeExamples())
+	if c.PromptTimezone != "" {
+		if _, err := time.LoadLocation(c.PromptTimezone); err != nil {
+			add(fmt.Errorf("azurrr: invalid PROMPT_TIMEZONE: %w", err))
+		}
+	}
+	switch c.OverflowPolicy {
+	case "", OverflowQueue, OverflowReject:
+	default:
+		add(fmt.Errorf("azurrr: unknown CONCURRENCY_OVERFLOW_POLICY %q", c.OverflowPolicy))
+	}
+	return errors.Join(errs...)
+}
+
+// checkConflicts reports settings that contradict each other, where one
+// would otherwise be silently ignored.
+func (c Config) checkConflicts() error {
+	var errs []error
+	if c.Endpoint != "" && c.ResourceName != "" {
+		errs = append(errs, errors.New("azurrr: AOAI_ENDPOINT_URL and AOAI_RESOURCE_NAME are both set; set one"))
+	}
+	if c.SearchAPIKey != "" && c.SearchAuth != "" && c.SearchAuth != AuthAPIKey {
+		errs = append(errs, fmt.Errorf("azurrr: SEARCH_KEY is set but SEARCH_AUTH_TYPE is %q", c.SearchAuth))
+	}
+	if c.EmbeddingAPIKey != "" && c.EmbeddingAuth == AuthAccessToken {
+		errs = append(errs, fmt.Errorf("azurrr: EMBEDDING_API_KEY is set but EMBEDDING_AUTH_TYPE is %q", c.EmbeddingAuth))
+	}
+	return errors.Join(errs...)
+}
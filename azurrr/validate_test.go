@@ -0,0 +1,73 @@
+package azurrr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateReportsConflicts(t *testing.T) {
+	cfg := Config{
+		APIKey:               "key",
+		Endpoint:             "https://example.openai.azure.com",
+		ResourceName:         "example",
+		Deployment:           "gpt-4o",
+		SearchEndpoint:       "https://example.search.windows.net",
+		SearchIndex:          "docs",
+		SearchAPIKey:         "search-key",
+		SearchAuth:           AuthAccessToken,
+		SearchAccessToken:    "token",
+		EmbeddingEndpoint:    "https://example.openai.azure.com/openai/deployments/ada/embeddings",
+		EmbeddingAPIKey:      "embedding-key",
+		EmbeddingAuth:        AuthAccessToken,
+		EmbeddingAccessToken: "token",
+		SearchQueryType:      "vector",
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate succeeded")
+	}
+	for _, want := range []string{"AOAI_ENDPOINT_URL and AOAI_RESOURCE_NAME", "SEARCH_KEY", "EMBEDDING_API_KEY"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestValidateAcceptsConsistentConfig(t *testing.T) {
+	cfg := Config{
+		APIKey:         "key",
+		ResourceName:   "example",
+		Deployment:     "gpt-4o",
+		SearchEndpoint: "https://example.search.windows.net",
+		SearchIndex:    "docs",
+		SearchAPIKey:   "search-key",
+		SearchAuth:     AuthAPIKey,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestConfigFromFileWithoutAPIKey(t *testing.T) {
+	for _, key := range []string{"AZURE_OPENAI_API_KEY", "AOAI_ENDPOINT_URL", "AOAI_RESOURCE_NAME", "DEPLOYMENT_NAME"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "AOAI_ENDPOINT_URL: https://example.openai.azure.com\nDEPLOYMENT_NAME: gpt-4o\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("ConfigFromFile: %v", err)
+	}
+	if cfg.Deployment != "gpt-4o" {
+		t.Errorf("Deployment = %q, want gpt-4o", cfg.Deployment)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AZURE_OPENAI_API_KEY") {
+		t.Errorf("Validate = %v, want the missing API key reported", err)
+	}
+}
@@ -0,0 +1,6 @@
+package azurrr
+
+const (
+	moduleName    = "azurrr"
+	moduleVersion = "v0.1.0"
+)
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// update makes AssertGolden rewrite golden files instead of comparing:
+//
+//	go test ./azurrr -update
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// secretFields are the wire fields whose values are replaced by "<secret>".
+var secretFields = map[string]bool{"key": true, "access_token": true}
+
+// volatileText matches the parts of message content that change from run to
+// run, such as the date line of the system prompt.
+var volatileText = regexp.MustCompile(`The current date and time is [^(]+\(`)
+
+// RequestJSON serializes a request's wire fields, as the azurrr package
+// builds them from a ChatCompletionsOptions, indented with sorted keys and
+// unset fields left out, with secrets and volatile text normalized so the
+// output is stable. Taking the fields rather than the options keeps the
+// list of wire names in one place.
+func RequestJSON(fields map[string]any) ([]byte, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var wire map[string]any
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, err
+	}
+	for k, v := range wire {
+		if v == nil {
+			delete(wire, k)
+		}
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(normalize("", wire)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func normalize(field string, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = normalize(k, child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = normalize(field, child)
+		}
+	case string:
+		if secretFields[field] {
+			return "<secret>"
+		}
+		return volatileText.ReplaceAllString(v, "The current date and time is <now> (")
+	}
+	return v
+}
+
+// AssertRequest compares the JSON of a request's wire fields with the
+// golden file at path, conventionally under testdata.
+func AssertRequest(t testing.TB, path string, fields map[string]any) {
+	t.Helper()
+	got, err := RequestJSON(fields)
+	if err != nil {
+		t.Fatalf("serializing request: %v", err)
+	}
+	AssertGolden(t, path, got)
+}
+
+// AssertGolden compares got with the golden file at path, or writes got to
+// it when the tests run with -update.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s differs from the golden file (run with -update to accept):\n%s", path, firstDiff(want, got))
+	}
+}
+
+// firstDiff describes the first line where want and got differ.
+func firstDiff(want, got []byte) string {
+	w := strings.Split(string(want), "\n")
+	g := strings.Split(string(got), "\n")
+	for i := 0; i < max(len(w), len(g)); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, wl, gl)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+// Package test builds fake Azure OpenAI chat responses so result parsing can
+// be exercised without a real deployment or search index.
+//
+// Contributor tests in the azurrr package build a response, feed it to the
+// parsing under test and assert on the CompletionResult, typically as a
+// table:
+//
+//	resp := test.NewResponse().
+//		Content("Paris is the capital [doc1].").
+//		Citation("France", "https://example.com/fr", "Paris is ...").
+//		Intent("capital of France").
+//		Build()
+//	result := newCompletionResult(resp)
+//
+// Stream turns the same response into chunks, and ChunkReader replays them
+// with an optional injected error, for the streaming code paths.
+//
+// AssertRequest checks the other direction: it compares the wire fields of a
+// built ChatCompletionsOptions with a golden file under testdata, normalizing
+// secrets and the prompt's date line, and rewrites the file when the tests
+// run with -update.
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"io"
+)
+
+// ResponseBuilder assembles a GetChatCompletionsResponse with a single
+// assistant choice. The zero value is not usable; call NewResponse.
+type ResponseBuilder struct {
+	content      string
+	refusal      *string
+	finishReason azopenai.CompletionsFinishReason
+	noChoices    bool
+	context      *azopenai.AzureChatExtensionsMessageContext
+	filters      []azopenai.ContentFilterResultsForPrompt
+	usage        *azopenai.CompletionsUsage
+}
+
+// NewResponse starts a response that finished normally with empty content.
+func NewResponse() *ResponseBuilder {
+	return &ResponseBuilder{finishReason: azopenai.CompletionsFinishReasonStopped}
+}
+
+func (b *ResponseBuilder) Content(s string) *ResponseBuilder {
+	b.content = s
+	return b
+}
+
+func (b *ResponseBuilder) Refusal(s string) *ResponseBuilder {
+	b.refusal = &s
+	return b
+}
+
+func (b *ResponseBuilder) FinishReason(r azopenai.CompletionsFinishReason) *ResponseBuilder {
+	b.finishReason = r
+	return b
+}
+
+// NoChoices makes Build return an empty choices array, as when every choice
+// was filtered.
+func (b *ResponseBuilder) NoChoices() *ResponseBuilder {
+	b.noChoices = true
+	return b
+}
+
+func (b *ResponseBuilder) messageContext() *azopenai.AzureChatExtensionsMessageContext {
+	if b.context == nil {
+		b.context = &azopenai.AzureChatExtensionsMessageContext{}
+	}
+	return b.context
+}
+
+// Citation appends a citation to the extension context.
+func (b *ResponseBuilder) Citation(title, url, content string) *ResponseBuilder {
+	ctx := b.messageContext()
+	ctx.Citations = append(ctx.Citations, azopenai.AzureChatExtensionDataSourceResponseCitation{
+		Title:   to.Ptr(title),
+		URL:     to.Ptr(url),
+		Content: to.Ptr(content),
+	})
+	return b
+}
+
+// RetrievedDocument appends a document retrieved from data source
+// sourceIndex by the given search queries.
+func (b *ResponseBuilder) RetrievedDocument(sourceIndex int32, title, content string, queries ...string) *ResponseBuilder {
+	ctx := b.messageContext()
+	ctx.AllRetrievedDocuments = append(ctx.AllRetrievedDocuments, azopenai.AzureChatExtensionRetrievedDocument{
+		Title:           to.Ptr(title),
+		Content:         to.Ptr(content),
+		DataSourceIndex: to.Ptr(sourceIndex),
+		SearchQueries:   queries,
+	})
+	return b
+}
+
+// Intent sets the intent to the given search queries, encoded the way the
+// service sends it.
+func (b *ResponseBuilder) Intent(queries ...string) *ResponseBuilder {
+	data, _ := json.Marshal(queries)
+	b.messageContext().Intent = to.Ptr(string(data))
+	return b
+}
+
+// PromptFilter appends a prompt filter result for prompt 0 marking category
+// as filtered. category is one of "hate", "sexual", "violence" or
+// "selfharm".
+func (b *ResponseBuilder) PromptFilter(category string) *ResponseBuilder {
+	filtered := &azopenai.ContentFilterResult{Filtered: to.Ptr(true), Severity: to.Ptr(azopenai.ContentFilterSeverityHigh)}
+	details := &azopenai.ContentFilterResultDetailsForPrompt{}
+	switch category {
+	case "hate":
+		details.Hate = filtered
+	case "sexual":
+		details.Sexual = filtered
+	case "violence":
+		details.Violence = filtered
+	case "selfharm":
+		details.SelfHarm = filtered
+	}
+	b.filters = append(b.filters, azopenai.ContentFilterResultsForPrompt{
+		PromptIndex:          to.Ptr[int32](0),
+		ContentFilterResults: details,
+	})
+	return b
+}
+
+func (b *ResponseBuilder) Usage(prompt, completion int32) *ResponseBuilder {
+	b.usage = &azopenai.CompletionsUsage{
+		PromptTokens:     to.Ptr(prompt),
+		CompletionTokens: to.Ptr(completion),
+		TotalTokens:      to.Ptr(prompt + completion),
+	}
+	return b
+}
+
+// Build returns the non-streaming response.
+func (b *ResponseBuilder) Build() azopenai.GetChatCompletionsResponse {
+	resp := azopenai.GetChatCompletionsResponse{}
+	resp.ID = to.Ptr("chatcmpl-test")
+	resp.PromptFilterResults = b.filters
+	resp.Usage = b.usage
+	if b.noChoices {
+		resp.Choices = []azopenai.ChatChoice{}
+		return resp
+	}
+	resp.Choices = []azopenai.ChatChoice{{
+		Index:        to.Ptr[int32](0),
+		FinishReason: to.Ptr(b.finishReason),
+		Message: &azopenai.ChatResponseMessage{
+			Role:    to.Ptr(azopenai.ChatRoleAssistant),
+			Content: to.Ptr(b.content),
+			Refusal: b.refusal,
+			Context: b.context,
+		},
+	}}
+	return resp
+}
+
+// Stream returns the response as streaming chunks: the role and extension
+// context first, then one chunk per word of content, then the finish reason
+// and usage.
+func (b *ResponseBuilder) Stream() []azopenai.ChatCompletions {
+	chunks := []azopenai.ChatCompletions{{Choices: []azopenai.ChatChoice{{
+		Index: to.Ptr[int32](0),
+		Delta: &azopenai.ChatResponseMessage{Role: to.Ptr(azopenai.ChatRoleAssistant), Context: b.context},
+	}}}}
+	for _, piece := range splitKeepingSpaces(b.content) {
+		chunks = append(chunks, azopenai.ChatCompletions{Choices: []azopenai.ChatChoice{{
+			Index: to.Ptr[int32](0),
+			Delta: &azopenai.ChatResponseMessage{Content: to.Ptr(piece)},
+		}}})
+	}
+	return append(chunks, azopenai.ChatCompletions{
+		Choices: []azopenai.ChatChoice{{Index: to.Ptr[int32](0), FinishReason: to.Ptr(b.finishReason), Delta: &azopenai.ChatResponseMessage{}}},
+		Usage:   b.usage,
+	})
+}
+
+func splitKeepingSpaces(s string) []string {
+	var out []string
+	start := 0
+	for i, r := range s {
+		if r == ' ' && i > start {
+			out = append(out, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		out = append(out, s[start:])
+	}
+	return out
+}
+
+// ErrInjected is the error ChunkReader returns when FailAfter is reached.
+var ErrInjected = errors.New("test: injected stream error")
+
+// ChunkReader replays Chunks like the SDK's event reader. When FailAfter is
+// positive, the read after that many chunks returns Err (ErrInjected if nil)
+// instead of continuing.
+type ChunkReader struct {
+	Chunks    []azopenai.ChatCompletions
+	FailAfter int
+	Err       error
+	n         int
+}
+
+func (r *ChunkReader) Read() (azopenai.ChatCompletions, error) {
+	if r.FailAfter > 0 && r.n == r.FailAfter {
+		if r.Err != nil {
+			return azopenai.ChatCompletions{}, r.Err
+		}
+		return azopenai.ChatCompletions{}, ErrInjected
+	}
+	if r.n >= len(r.Chunks) {
+		return azopenai.ChatCompletions{}, io.EOF
+	}
+	r.n++
+	return r.Chunks[r.n-1], nil
+}